@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/genai"
+)
+
+// preserveToolArguments remembers the raw argument string of the tool call
+// with the given ID when PreserveToolArguments is set.
+func (o *OpenAIModel) preserveToolArguments(id, args string) {
+	if !o.PreserveToolArguments || id == "" {
+		return
+	}
+	o.toolArguments.Store(id, args)
+}
+
+// toolCallArguments returns the JSON arguments to replay for a function call
+// part. With PreserveToolArguments, the raw string the model emitted for the
+// call's ID is sent verbatim as long as it still decodes to the part's Args;
+// otherwise Args is marshaled afresh.
+func (o *OpenAIModel) toolCallArguments(part *genai.Part) (string, error) {
+	if raw, ok := o.toolArguments.Load(part.FunctionCall.ID); ok && o.PreserveToolArguments {
+		var args map[string]any
+		if err := json.Unmarshal([]byte(raw.(string)), &args); err == nil && reflect.DeepEqual(args, part.FunctionCall.Args) {
+			return raw.(string), nil
+		}
+	}
+	argsJSON, err := marshalJSON(part.FunctionCall.Args)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal function args: %w", err)
+	}
+	return string(argsJSON), nil
+}
@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestPreserveToolArguments(t *testing.T) {
+	const raw = `{"zeta": 1, "alpha": "x"}`
+	toolCall := openai.ToolCall{
+		ID:       "call_1",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "lookup", Arguments: raw},
+	}
+	builtinCall := openai.ToolCall{ID: "ws_1", Type: "web_search"}
+	index := 0
+	streamCall := toolCall
+	streamCall.Index = &index
+
+	// generated returns the model turn produced for the tool calls, either
+	// from a complete response or a stream, along with the model that
+	// produced it.
+	generated := func(t *testing.T, preserve, stream bool, toolCalls []openai.ToolCall) (*OpenAIModel, *genai.Content) {
+		t.Helper()
+		if stream {
+			m := &OpenAIModel{PreserveToolArguments: preserve}
+			resps := collectStream(t, m, &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{streamCall}}, openai.FinishReasonToolCalls),
+			}})
+			return m, resps[len(resps)-1].Content
+		}
+		server, m := newFakeServer(t)
+		server.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: toolCalls},
+			FinishReason: openai.FinishReasonToolCalls,
+		}}}
+		m.PreserveToolArguments = preserve
+		var content *genai.Content
+		for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+			Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Look it up"}}}},
+		}, false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			content = resp.Content
+		}
+		return m, content
+	}
+
+	tests := []struct {
+		name      string
+		preserve  bool
+		stream    bool
+		edit      bool
+		toolCalls []openai.ToolCall
+		want      string
+	}{
+		{name: "re-marshaled by default", want: `{"alpha":"x","zeta":1}`},
+		{name: "preserved", preserve: true, want: raw},
+		{name: "preserved from stream", preserve: true, stream: true, want: raw},
+		{name: "edited args are re-marshaled", preserve: true, edit: true, want: `{"alpha":"y","zeta":1}`},
+		{name: "paired by ID after a built-in call", preserve: true, toolCalls: []openai.ToolCall{builtinCall, toolCall}, want: raw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolCalls := tt.toolCalls
+			if toolCalls == nil {
+				toolCalls = []openai.ToolCall{toolCall}
+			}
+			m, content := generated(t, tt.preserve, tt.stream, toolCalls)
+			for _, part := range content.Parts {
+				if part.ThoughtSignature != nil {
+					t.Errorf("ThoughtSignature = %q, want none", part.ThoughtSignature)
+				}
+			}
+			call := content.Parts[len(content.Parts)-1]
+			if tt.edit {
+				call.FunctionCall.Args["alpha"] = "y"
+			}
+
+			msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{Role: "model", Parts: []*genai.Part{call}})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if len(msgs) != 1 || len(msgs[0].ToolCalls) != 1 {
+				t.Fatalf("got %+v, want one message with one tool call", msgs)
+			}
+			if got := msgs[0].ToolCalls[0].Function.Arguments; got != tt.want {
+				t.Errorf("replayed arguments = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_ArgumentsNotHTMLEscaped(t *testing.T) {
+	const query = `a < b && c > d`
+	m := &OpenAIModel{}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "model",
+		Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+			ID:   "call_1",
+			Name: "search",
+			Args: map[string]any{"query": query},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+	if got, want := msgs[0].ToolCalls[0].Function.Arguments, `{"query":"a < b && c > d"}`; got != want {
+		t.Errorf("Arguments = %s, want %s", got, want)
+	}
+
+	msgs, err = m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       "call_1",
+			Name:     "search",
+			Response: map[string]any{"query": query, "hits": 0},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+	if got, want := msgs[0].Content, `{"hits":0,"query":"a < b && c > d"}`; got != want {
+		t.Errorf("Content = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,24 @@
+package openai
+
+import "github.com/sashabaranov/go-openai"
+
+// NewAzureOpenAIModel returns a model served by the Azure OpenAI deployment
+// named deployment at endpoint, e.g. https://my-resource.openai.azure.com.
+// Requests authenticate with the api-key header and carry apiVersion as the
+// api-version query parameter; an empty apiVersion keeps go-openai's default.
+func NewAzureOpenAIModel(deployment, endpoint, apiKey, apiVersion string, opts ...Option) *OpenAIModel {
+	return NewOpenAIModel(deployment, azureConfig(endpoint, apiKey, apiVersion), opts...)
+}
+
+// azureConfig returns a client config for Azure OpenAI that uses the model
+// name verbatim as the deployment name.
+func azureConfig(endpoint, apiKey, apiVersion string) openai.ClientConfig {
+	cfg := openai.DefaultAzureConfig(apiKey, endpoint)
+	if apiVersion != "" {
+		cfg.APIVersion = apiVersion
+	}
+	cfg.AzureModelMapperFunc = func(model string) string {
+		return model
+	}
+	return cfg
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestAzureConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiVersion     string
+		wantAPIVersion string
+	}{
+		{name: "explicit version", apiVersion: "2024-10-21", wantAPIVersion: "2024-10-21"},
+		{name: "default version", apiVersion: "", wantAPIVersion: openai.DefaultAzureConfig("", "").APIVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := azureConfig("https://my-resource.openai.azure.com", "key", tt.apiVersion)
+			if cfg.APIType != openai.APITypeAzure {
+				t.Errorf("APIType = %q, want %q", cfg.APIType, openai.APITypeAzure)
+			}
+			if cfg.BaseURL != "https://my-resource.openai.azure.com" {
+				t.Errorf("BaseURL = %q, want https://my-resource.openai.azure.com", cfg.BaseURL)
+			}
+			if cfg.APIVersion != tt.wantAPIVersion {
+				t.Errorf("APIVersion = %q, want %q", cfg.APIVersion, tt.wantAPIVersion)
+			}
+			if got := cfg.AzureModelMapperFunc("gpt-4.1-prod"); got != "gpt-4.1-prod" {
+				t.Errorf("AzureModelMapperFunc() = %q, want deployment name unchanged", got)
+			}
+		})
+	}
+}
+
+func TestNewAzureOpenAIModel(t *testing.T) {
+	s, _ := newFakeServer(t)
+	m := NewAzureOpenAIModel("gpt-4.1-prod", s.url, "secret", "2024-10-21")
+	if m.Name() != "gpt-4.1-prod" {
+		t.Errorf("Name() = %q, want gpt-4.1-prod", m.Name())
+	}
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}
+	for _, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+	}
+
+	sent := s.requestsTo("/chat/completions")[0]
+	if want := "/openai/deployments/gpt-4.1-prod/chat/completions"; sent.url.Path != want {
+		t.Errorf("path = %q, want %q", sent.url.Path, want)
+	}
+	if got := sent.url.Query().Get("api-version"); got != "2024-10-21" {
+		t.Errorf("api-version = %q, want 2024-10-21", got)
+	}
+	if got := sent.header.Get("api-key"); got != "secret" {
+		t.Errorf("api-key = %q, want secret", got)
+	}
+}
@@ -0,0 +1,40 @@
+package openai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// versionSuffixPattern matches one or more trailing API version segments such
+// as /v1 or /v1beta.
+var versionSuffixPattern = regexp.MustCompile(`(/v[0-9]+[a-z0-9]*)+$`)
+
+// WithBaseURL points the client at baseURL instead of the URL in the client
+// config. The URL is normalized so that it ends in exactly one version
+// segment: trailing slashes are trimmed, a doubled /v1 is collapsed and a
+// missing one is appended. WithoutBaseURLNormalization keeps it verbatim.
+func WithBaseURL(baseURL string) Option {
+	return func(o *OpenAIModel) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithoutBaseURLNormalization sends requests to the URL given to WithBaseURL
+// as is, for gateways whose paths do not end in an API version, such as
+// Gemini's .../v1beta/openai.
+func WithoutBaseURLNormalization() Option {
+	return func(o *OpenAIModel) {
+		o.rawBaseURL = true
+	}
+}
+
+// normalizeBaseURL trims trailing slashes from baseURL and makes it end in a
+// single version segment, appending /v1 when there is none.
+func normalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if loc := versionSuffixPattern.FindStringIndex(baseURL); loc != nil {
+		segments := strings.Split(baseURL[loc[0]+1:], "/")
+		return baseURL[:loc[0]] + "/" + segments[len(segments)-1]
+	}
+	return baseURL + "/v1"
+}
@@ -0,0 +1,65 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "https://api.openai.com/v1", want: "https://api.openai.com/v1"},
+		{in: "https://api.openai.com/v1/", want: "https://api.openai.com/v1"},
+		{in: "https://api.openai.com", want: "https://api.openai.com/v1"},
+		{in: "https://api.openai.com/", want: "https://api.openai.com/v1"},
+		{in: "https://api.openai.com/v1/v1", want: "https://api.openai.com/v1"},
+		{in: "http://localhost:11434//", want: "http://localhost:11434/v1"},
+		{in: "https://openrouter.ai/api/v1", want: "https://openrouter.ai/api/v1"},
+		{in: "https://example.com/v2/", want: "https://example.com/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := normalizeBaseURL(tt.in); got != tt.want {
+				t.Errorf("normalizeBaseURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	s, _ := newFakeServer(t)
+	models := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[]}`))
+	}
+	s.handle("/v1/models", models)
+	s.handle("/gateway/models", models)
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantPath string
+	}{
+		{name: "normalized", opts: []Option{WithBaseURL(s.url + "/")}, wantPath: "/v1/models"},
+		{name: "verbatim", opts: []Option{WithBaseURL(s.url + "/gateway"), WithoutBaseURLNormalization()}, wantPath: "/gateway/models"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewOpenAIModel("gpt-4o", openai.DefaultConfig("test"), tt.opts...)
+			if _, err := m.Client.ListModels(context.Background()); err != nil {
+				t.Fatalf("ListModels() error = %v", err)
+			}
+			reqs := s.requestsTo("/models")
+			if path := reqs[len(reqs)-1].url.Path; path != tt.wantPath {
+				t.Errorf("request path = %q, want %q", path, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// batchCustomIDPrefix prefixes the index of each request in its batch
+// custom_id, so results can be matched to requests.
+const batchCustomIDPrefix = "request-"
+
+// Terminal batch statuses.
+const (
+	BatchStatusCompleted = "completed"
+	BatchStatusFailed    = "failed"
+	BatchStatusExpired   = "expired"
+	BatchStatusCancelled = "cancelled"
+)
+
+// BatchResult is the outcome of one request of a batch. Index is the
+// request's position in the slice given to SubmitBatch. Exactly one of
+// Response and Err is set.
+type BatchResult struct {
+	Index    int
+	Response *model.LLMResponse
+	Err      error
+}
+
+// SubmitBatch converts reqs into chat completion requests and submits them as
+// a single job to the Batch API, which processes them within 24 hours at a
+// reduced price. Use WaitBatch or RetrieveBatch to follow the job and
+// BatchResults to read its results.
+func (o *OpenAIModel) SubmitBatch(ctx context.Context, reqs []*model.LLMRequest) (openai.Batch, error) {
+	if len(reqs) == 0 {
+		return openai.Batch{}, errors.New("batch has no requests")
+	}
+	upload := openai.CreateBatchWithUploadFileRequest{Endpoint: openai.BatchEndpointChatCompletions}
+	for i, req := range reqs {
+		openaiReq, err := o.toOpenAIChatCompletionRequest(ctx, req)
+		if err != nil {
+			return openai.Batch{}, fmt.Errorf("request %d: %w", i, err)
+		}
+		upload.AddChatCompletion(batchCustomIDPrefix+strconv.Itoa(i), openaiReq)
+	}
+	resp, err := o.Client.CreateBatchWithUploadFile(ctx, upload)
+	if err != nil {
+		return openai.Batch{}, err
+	}
+	return resp.Batch, nil
+}
+
+// RetrieveBatch returns the current state of the batch with the given ID.
+func (o *OpenAIModel) RetrieveBatch(ctx context.Context, batchID string) (openai.Batch, error) {
+	resp, err := o.Client.RetrieveBatch(ctx, batchID)
+	if err != nil {
+		return openai.Batch{}, err
+	}
+	return resp.Batch, nil
+}
+
+// WaitBatch polls the batch with the given ID every interval until it reaches
+// a terminal status or ctx is done, and returns its final state.
+func (o *OpenAIModel) WaitBatch(ctx context.Context, batchID string, interval time.Duration) (openai.Batch, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		batch, err := o.RetrieveBatch(ctx, batchID)
+		if err != nil || batchDone(batch) {
+			return batch, err
+		}
+		select {
+		case <-ctx.Done():
+			return batch, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// batchDone reports whether batch has reached a terminal status.
+func batchDone(batch openai.Batch) bool {
+	switch batch.Status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// batchOutputLine is a line of a batch output or error file.
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// BatchResults downloads the output and error files of a finished batch and
+// converts every line into a BatchResult, ordered by request index. Requests
+// that never ran, as in an expired batch, have no result.
+func (o *OpenAIModel) BatchResults(ctx context.Context, batch openai.Batch) ([]BatchResult, error) {
+	var results []BatchResult
+	for _, fileID := range []*string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == nil || *fileID == "" {
+			continue
+		}
+		content, err := o.Client.GetFileContent(ctx, *fileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download batch file %s: %w", *fileID, err)
+		}
+		decoder := json.NewDecoder(content)
+		for decoder.More() {
+			var line batchOutputLine
+			if err := decoder.Decode(&line); err != nil {
+				content.Close()
+				return nil, fmt.Errorf("failed to decode batch file %s: %w", *fileID, err)
+			}
+			result, err := o.batchResult(line)
+			if err != nil {
+				content.Close()
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		content.Close()
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+	return results, nil
+}
+
+// batchResult converts a line of a batch output or error file.
+func (o *OpenAIModel) batchResult(line batchOutputLine) (BatchResult, error) {
+	index, err := strconv.Atoi(strings.TrimPrefix(line.CustomID, batchCustomIDPrefix))
+	if err != nil || !strings.HasPrefix(line.CustomID, batchCustomIDPrefix) {
+		return BatchResult{}, fmt.Errorf("unexpected batch custom_id %q", line.CustomID)
+	}
+	result := BatchResult{Index: index}
+
+	switch {
+	case line.Error != nil:
+		result.Err = fmt.Errorf("batch request failed: %s: %s", line.Error.Code, line.Error.Message)
+	case line.Response == nil:
+		result.Err = errors.New("batch request has no response")
+	case line.Response.StatusCode != http.StatusOK:
+		var errResp openai.ErrorResponse
+		if err := json.Unmarshal(line.Response.Body, &errResp); err != nil || errResp.Error == nil {
+			result.Err = fmt.Errorf("batch request failed with status %d: %s", line.Response.StatusCode, line.Response.Body)
+		} else {
+			errResp.Error.HTTPStatusCode = line.Response.StatusCode
+			result.Err = errResp.Error
+		}
+	default:
+		var resp openai.ChatCompletionResponse
+		if err := json.Unmarshal(line.Response.Body, &resp); err != nil {
+			result.Err = fmt.Errorf("failed to decode batch response: %w", err)
+			break
+		}
+		result.Response, result.Err = convertChatCompletionResponse(&resp, o.finishReason)
+		if result.Err == nil {
+			if result.Err = o.refusalError(result.Response); result.Err != nil {
+				result.Response = nil
+			}
+		}
+	}
+	return result, nil
+}
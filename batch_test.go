@@ -0,0 +1,144 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// handleBatchEndpoints serves the Files and Batch API endpoints SubmitBatch,
+// WaitBatch and BatchResults use from s, answering with the given output and
+// error files. The batch completes on the second poll. Uploaded requests are
+// appended to lines.
+func handleBatchEndpoints(t *testing.T, s *fakeServer, lines *[]openai.BatchChatCompletionRequest, output, errors string) {
+	t.Helper()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	s.handle("/files", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("failed to read uploaded file: %v", err)
+			return
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var line openai.BatchChatCompletionRequest
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Errorf("failed to decode batch line: %v", err)
+			}
+			*lines = append(*lines, line)
+		}
+		writeJSON(w, openai.File{ID: "file-in", Purpose: string(openai.PurposeBatch)})
+	})
+	s.handle("/batches", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, openai.Batch{ID: "batch_1", Status: "validating", InputFileID: "file-in"})
+	})
+	s.handle("/batches/batch_1", func(w http.ResponseWriter, r *http.Request) {
+		batch := openai.Batch{ID: "batch_1", Status: "in_progress"}
+		if len(s.requestsTo("/batches/batch_1")) >= 2 {
+			batch.Status = BatchStatusCompleted
+			batch.OutputFileID = genai.Ptr("file-out")
+			batch.ErrorFileID = genai.Ptr("file-err")
+		}
+		writeJSON(w, batch)
+	})
+	s.handle("/files/file-out/content", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, output)
+	})
+	s.handle("/files/file-err/content", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, errors)
+	})
+}
+
+func TestBatch(t *testing.T) {
+	body := func(text string) string {
+		resp := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: text},
+			FinishReason: openai.FinishReasonStop,
+		}}}
+		data, _ := json.Marshal(resp)
+		return string(data)
+	}
+	s, m := newFakeServer(t)
+	m.ModelName = "gpt-4o-mini"
+	var lines []openai.BatchChatCompletionRequest
+	handleBatchEndpoints(t, s, &lines,
+		fmt.Sprintf(`{"custom_id":"request-2","response":{"status_code":200,"body":%s}}
+{"custom_id":"request-0","response":{"status_code":200,"body":%s}}
+`, body("Three"), body("One")),
+		`{"custom_id":"request-1","response":{"status_code":400,"body":{"error":{"message":"bad request","type":"invalid_request_error"}}}}`,
+	)
+
+	var reqs []*model.LLMRequest
+	for _, text := range []string{"one", "two", "three"} {
+		reqs = append(reqs, &model.LLMRequest{
+			Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: text}}}},
+		})
+	}
+
+	ctx := context.Background()
+	batch, err := m.SubmitBatch(ctx, reqs)
+	if err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+	if batch.ID != "batch_1" {
+		t.Errorf("batch ID = %q, want batch_1", batch.ID)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("uploaded %d lines, want 3", len(lines))
+	}
+	for i, line := range lines {
+		if line.CustomID != fmt.Sprintf("request-%d", i) || line.URL != openai.BatchEndpointChatCompletions {
+			t.Errorf("line %d = %s %s, want request-%d for chat completions", i, line.CustomID, line.URL, i)
+		}
+		if line.Body.Model != "gpt-4o-mini" || line.Body.Messages[0].Content != reqs[i].Contents[0].Parts[0].Text {
+			t.Errorf("line %d body = %+v, want the converted request", i, line.Body)
+		}
+	}
+
+	batch, err = m.WaitBatch(ctx, batch.ID, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitBatch() error = %v", err)
+	}
+	if polls := len(s.requestsTo("/batches/batch_1")); batch.Status != BatchStatusCompleted || polls != 2 {
+		t.Errorf("WaitBatch() status = %q after %d polls, want completed after 2", batch.Status, polls)
+	}
+
+	results, err := m.BatchResults(ctx, batch)
+	if err != nil {
+		t.Fatalf("BatchResults() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, want := range []string{"One", "", "Three"} {
+		result := results[i]
+		if result.Index != i {
+			t.Errorf("result %d has index %d", i, result.Index)
+		}
+		if want == "" {
+			if result.Err == nil || !strings.Contains(result.Err.Error(), "bad request") {
+				t.Errorf("result %d error = %v, want bad request", i, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Fatalf("result %d error = %v", i, result.Err)
+		}
+		if got := result.Response.Content.Parts[0].Text; got != want {
+			t.Errorf("result %d text = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package openai
+
+// BuiltinTool names a tool OpenAI runs itself rather than the caller. Built-in
+// tools exist only on the Responses API, so they are offered through
+// OpenAIResponsesModel.BuiltinTools.
+type BuiltinTool string
+
+const (
+	// BuiltinWebSearch lets the model search the web.
+	BuiltinWebSearch BuiltinTool = "web_search_preview"
+)
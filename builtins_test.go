@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestOpenAIResponsesModel_BuiltinWebSearch(t *testing.T) {
+	client := &fakeResponsesClient{resp: &responsesResponse{
+		Status: "completed",
+		Output: []responsesItem{
+			{Type: "web_search_call"},
+			{Type: "message", Role: "assistant", Content: []responsesContent{{Type: "output_text", Text: "Sunny in Paris."}}},
+		},
+	}}
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1", BuiltinTools: []BuiltinTool{BuiltinWebSearch}, client: client}
+
+	got := generateOnce(t, m, weatherRequest())
+
+	body, err := json.Marshal(client.req.Tools)
+	if err != nil {
+		t.Fatalf("failed to marshal tools: %v", err)
+	}
+	var tools []map[string]any
+	if err := json.Unmarshal(body, &tools); err != nil {
+		t.Fatalf("failed to decode tools: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("got %d tools, want get_weather and web search", len(tools))
+	}
+	if tools[0]["name"] != "get_weather" {
+		t.Errorf("tools[0] = %v, want get_weather", tools[0])
+	}
+	if diff := cmp.Diff(map[string]any{"type": "web_search_preview"}, tools[1]); diff != "" {
+		t.Errorf("tools[1] mismatch (-want +got):\n%s", diff)
+	}
+
+	// The search ran on OpenAI's side, so only its answer comes back
+	wantParts := []*genai.Part{{Text: "Sunny in Paris."}}
+	if diff := cmp.Diff(wantParts, got.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+}
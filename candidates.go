@@ -0,0 +1,146 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// candidateCount returns the number of candidates cfg asks for.
+func candidateCount(cfg *genai.GenerateContentConfig) int {
+	return int(cfg.CandidateCount)
+}
+
+// fanOutChatCompletion sends openaiReq n times in parallel and merges the
+// responses as if a single request with n choices had been made.
+func (o *OpenAIModel) fanOutChatCompletion(ctx context.Context, openaiReq openai.ChatCompletionRequest, n int) (openai.ChatCompletionResponse, error) {
+	resps := make([]openai.ChatCompletionResponse, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i], errs[i] = o.Client.CreateChatCompletion(ctx, openaiReq)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	return mergeChatCompletionResponses(resps), nil
+}
+
+// mergeChatCompletionResponses combines the choices of resps into a single
+// response, renumbering choice indices and summing usage.
+func mergeChatCompletionResponses(resps []openai.ChatCompletionResponse) openai.ChatCompletionResponse {
+	merged := resps[0]
+	merged.Choices = nil
+	merged.Usage = openai.Usage{}
+	for _, resp := range resps {
+		for _, choice := range resp.Choices {
+			choice.Index = len(merged.Choices)
+			merged.Choices = append(merged.Choices, choice)
+		}
+		merged.Usage = addUsage(merged.Usage, resp.Usage)
+	}
+	return merged
+}
+
+// addUsage returns the sum of a and b, including the token details.
+func addUsage(a, b openai.Usage) openai.Usage {
+	sum := openai.Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+	if a.PromptTokensDetails != nil || b.PromptTokensDetails != nil {
+		sum.PromptTokensDetails = &openai.PromptTokensDetails{}
+		for _, d := range []*openai.PromptTokensDetails{a.PromptTokensDetails, b.PromptTokensDetails} {
+			if d != nil {
+				sum.PromptTokensDetails.AudioTokens += d.AudioTokens
+				sum.PromptTokensDetails.CachedTokens += d.CachedTokens
+			}
+		}
+	}
+	if a.CompletionTokensDetails != nil || b.CompletionTokensDetails != nil {
+		sum.CompletionTokensDetails = &openai.CompletionTokensDetails{}
+		for _, d := range []*openai.CompletionTokensDetails{a.CompletionTokensDetails, b.CompletionTokensDetails} {
+			if d != nil {
+				sum.CompletionTokensDetails.AudioTokens += d.AudioTokens
+				sum.CompletionTokensDetails.ReasoningTokens += d.ReasoningTokens
+				sum.CompletionTokensDetails.AcceptedPredictionTokens += d.AcceptedPredictionTokens
+				sum.CompletionTokensDetails.RejectedPredictionTokens += d.RejectedPredictionTokens
+			}
+		}
+	}
+	return sum
+}
+
+// CandidateGroupsMetadataKey is the LLMResponse.CustomMetadata key under which
+// the candidates are stored grouped by identical text as []CandidateGroup when
+// OpenAIModel.GroupCandidates is set and more than one candidate was returned.
+const CandidateGroupsMetadataKey = "candidate_groups"
+
+// CandidateGroup is a set of candidates whose text is identical once leading
+// and trailing whitespace is trimmed. Thought parts are ignored.
+type CandidateGroup struct {
+	Text string
+	// Count is the number of candidates in the group.
+	Count int
+	// Indices holds the candidate indices in the group in ascending order.
+	Indices []int32
+}
+
+// applyCandidateGroups stores the candidates of resp grouped by text under
+// CandidateGroupsMetadataKey, most frequent first and ties in order of first
+// appearance.
+func (o *OpenAIModel) applyCandidateGroups(resp *model.LLMResponse) {
+	if !o.GroupCandidates {
+		return
+	}
+	candidates, ok := resp.CustomMetadata[CandidatesMetadataKey].([]*genai.Candidate)
+	if !ok {
+		return
+	}
+
+	var groups []CandidateGroup
+	byText := map[string]int{}
+	for _, candidate := range candidates {
+		text := strings.TrimSpace(candidateText(candidate.Content))
+		i, ok := byText[text]
+		if !ok {
+			i = len(groups)
+			byText[text] = i
+			groups = append(groups, CandidateGroup{Text: text})
+		}
+		groups[i].Count++
+		groups[i].Indices = append(groups[i].Indices, candidate.Index)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	setCustomMetadata(resp, CandidateGroupsMetadataKey, groups)
+}
+
+// candidateText joins the text of the non-thought parts of content.
+func candidateText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, part := range content.Parts {
+		if !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,244 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_CandidateCount(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   &genai.GenerateContentConfig{CandidateCount: 3},
+	}
+
+	tests := []struct {
+		name  string
+		m     *OpenAIModel
+		wantN int
+	}{
+		{name: "native", m: &OpenAIModel{ModelName: "gpt-4o"}, wantN: 3},
+		{name: "fan-out", m: &OpenAIModel{ModelName: "gpt-4o", FanOutCandidates: true}, wantN: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.N != tt.wantN {
+				t.Errorf("N = %d, want %d", got.N, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestGenerate_FanOutCandidatesSumsUsage(t *testing.T) {
+	var calls atomic.Int32
+	s, m := newFakeServer(t)
+	s.respond = func(req openai.ChatCompletionRequest) openai.ChatCompletionResponse {
+		if req.N != 0 {
+			t.Errorf("fan-out request N = %d, want 0", req.N)
+		}
+		call := int(calls.Add(1))
+		return openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: fmt.Sprintf("answer %d", call)},
+				FinishReason: openai.FinishReasonStop,
+			}},
+			Usage: openai.Usage{
+				PromptTokens:            10,
+				CompletionTokens:        call,
+				TotalTokens:             10 + call,
+				PromptTokensDetails:     &openai.PromptTokensDetails{CachedTokens: 4},
+				CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: call},
+			},
+		}
+	}
+	m.FanOutCandidates = true
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   &genai.GenerateContentConfig{CandidateCount: 3},
+	}
+	var resps []*model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resps = append(resps, resp)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server received %d requests, want 3", got)
+	}
+	if len(resps) != 1 {
+		t.Fatalf("got %d responses, want 1", len(resps))
+	}
+
+	// Prompt 10 and completion 1, 2, 3 tokens across the three calls.
+	wantUsage := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        30,
+		CandidatesTokenCount:    6,
+		TotalTokenCount:         36,
+		CachedContentTokenCount: 12,
+		ThoughtsTokenCount:      6,
+	}
+	if diff := cmp.Diff(wantUsage, resps[0].UsageMetadata); diff != "" {
+		t.Errorf("UsageMetadata mismatch (-want +got):\n%s", diff)
+	}
+
+	candidates, ok := resps[0].CustomMetadata[CandidatesMetadataKey].([]*genai.Candidate)
+	if !ok || len(candidates) != 3 {
+		t.Fatalf("CustomMetadata[%q] = %v, want 3 candidates", CandidatesMetadataKey, resps[0].CustomMetadata[CandidatesMetadataKey])
+	}
+	for i, candidate := range candidates {
+		if int(candidate.Index) != i {
+			t.Errorf("candidate %d has Index %d", i, candidate.Index)
+		}
+	}
+}
+
+func TestMergeChatCompletionResponses(t *testing.T) {
+	resps := []openai.ChatCompletionResponse{
+		{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "a"}}},
+			Usage:   openai.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+		},
+		{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "b"}}},
+			Usage: openai.Usage{
+				PromptTokens:            5,
+				CompletionTokens:        3,
+				TotalTokens:             8,
+				CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: 1},
+			},
+		},
+	}
+
+	got := mergeChatCompletionResponses(resps)
+
+	want := openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{Index: 0, Message: openai.ChatCompletionMessage{Content: "a"}},
+			{Index: 1, Message: openai.ChatCompletionMessage{Content: "b"}},
+		},
+		Usage: openai.Usage{
+			PromptTokens:            10,
+			CompletionTokens:        5,
+			TotalTokens:             15,
+			CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: 1},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(openai.ChatCompletionResponse{})); diff != "" {
+		t.Errorf("mergeChatCompletionResponses() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerate_GroupCandidates(t *testing.T) {
+	answers := []string{"42", "41", " 42\n", "43", "41", "42"}
+	newModel := func(group bool) *OpenAIModel {
+		s, m := newFakeServer(t)
+		s.response = openai.ChatCompletionResponse{}
+		for i, answer := range answers {
+			s.response.Choices = append(s.response.Choices, openai.ChatCompletionChoice{
+				Index:        i,
+				Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer},
+				FinishReason: openai.FinishReasonStop,
+			})
+		}
+		m.GroupCandidates = group
+		return m
+	}
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "What is 6 times 7?"}}}},
+		Config:   &genai.GenerateContentConfig{CandidateCount: int32(len(answers))},
+	}
+
+	tests := []struct {
+		name  string
+		group bool
+		want  any
+	}{
+		{name: "off by default", group: false, want: nil},
+		{
+			name:  "grouped",
+			group: true,
+			want: []CandidateGroup{
+				{Text: "42", Count: 3, Indices: []int32{0, 2, 5}},
+				{Text: "41", Count: 2, Indices: []int32{1, 4}},
+				{Text: "43", Count: 1, Indices: []int32{3}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for resp, err := range newModel(tt.group).GenerateContent(context.Background(), req, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, resp.CustomMetadata[CandidateGroupsMetadataKey]); diff != "" {
+					t.Errorf("candidate groups mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertChatCompletionResponse_CandidateIndex(t *testing.T) {
+	choice := func(index int, text string) openai.ChatCompletionChoice {
+		return openai.ChatCompletionChoice{
+			Index:        index,
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: text},
+			FinishReason: openai.FinishReasonStop,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		choices []openai.ChatCompletionChoice
+		want    []string
+	}{
+		{name: "in order", choices: []openai.ChatCompletionChoice{choice(0, "a"), choice(1, "b")}, want: []string{"a", "b"}},
+		{name: "out of order", choices: []openai.ChatCompletionChoice{choice(1, "b"), choice(0, "a"), choice(2, "c")}, want: []string{"a", "b", "c"}},
+		{name: "repeated index", choices: []openai.ChatCompletionChoice{choice(0, "a"), choice(0, "b")}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := convertChatCompletionResponse(&openai.ChatCompletionResponse{Choices: tt.choices}, convertFinishReason)
+			if err != nil {
+				t.Fatalf("convertChatCompletionResponse() error = %v", err)
+			}
+			if got := resp.Content.Parts[0].Text; got != tt.want[0] {
+				t.Errorf("Content text = %q, want %q", got, tt.want[0])
+			}
+			if resp.GroundingMetadata != nil || resp.CitationMetadata != nil {
+				t.Errorf("GroundingMetadata = %v, CitationMetadata = %v, want nil", resp.GroundingMetadata, resp.CitationMetadata)
+			}
+
+			candidates, ok := resp.CustomMetadata[CandidatesMetadataKey].([]*genai.Candidate)
+			if !ok || len(candidates) != len(tt.want) {
+				t.Fatalf("CustomMetadata[%q] = %v, want %d candidates", CandidatesMetadataKey, resp.CustomMetadata[CandidatesMetadataKey], len(tt.want))
+			}
+			for i, candidate := range candidates {
+				if candidate.Index != int32(i) {
+					t.Errorf("candidate %d has Index %d", i, candidate.Index)
+				}
+				if got := candidate.Content.Parts[0].Text; got != tt.want[i] {
+					t.Errorf("candidate %d text = %q, want %q", i, got, tt.want[i])
+				}
+				if candidate.GroundingMetadata != nil || candidate.CitationMetadata != nil {
+					t.Errorf("candidate %d has grounding or citation metadata", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package openai
+
+import "strings"
+
+const (
+	encodingCL100K = "cl100k_base"
+	encodingO200K  = "o200k_base"
+)
+
+// modelCapabilities describes request features that differ between model families.
+type modelCapabilities struct {
+	// maxCompletionTokens reports that the model rejects max_tokens and expects
+	// the output budget in max_completion_tokens instead.
+	maxCompletionTokens bool
+	// encoding is the tiktoken encoding used by the model's tokenizer.
+	encoding string
+	// noVision reports that the model rejects image input.
+	noVision bool
+}
+
+// modelFamilies maps model name prefixes to their capabilities. The first
+// matching prefix wins, so more specific prefixes must come first.
+var modelFamilies = []struct {
+	prefix string
+	caps   modelCapabilities
+}{
+	{prefix: "gpt-5", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+	{prefix: "gpt-4.5", caps: modelCapabilities{encoding: encodingO200K}},
+	{prefix: "gpt-4.1", caps: modelCapabilities{encoding: encodingO200K}},
+	{prefix: "gpt-4o", caps: modelCapabilities{encoding: encodingO200K}},
+	{prefix: "gpt-4-turbo", caps: modelCapabilities{encoding: encodingCL100K}},
+	{prefix: "gpt-4-vision", caps: modelCapabilities{encoding: encodingCL100K}},
+	{prefix: "gpt-4", caps: modelCapabilities{encoding: encodingCL100K, noVision: true}},
+	{prefix: "gpt-3.5", caps: modelCapabilities{encoding: encodingCL100K, noVision: true}},
+	{prefix: "o1-mini", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K, noVision: true}},
+	{prefix: "o1-preview", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K, noVision: true}},
+	{prefix: "o1", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+	{prefix: "o3-mini", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K, noVision: true}},
+	{prefix: "o3", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+	{prefix: "o4", caps: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+}
+
+// capabilitiesForModel returns the capabilities of the named model. Gateway
+// style names such as "openai/gpt-5" are matched on the part after the last
+// slash. Unknown models get the classic chat completions behavior most
+// compatible servers implement.
+func capabilitiesForModel(modelName string) modelCapabilities {
+	name := strings.ToLower(modelName)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, family := range modelFamilies {
+		if strings.HasPrefix(name, family.prefix) {
+			return family.caps
+		}
+	}
+	return modelCapabilities{encoding: encodingCL100K}
+}
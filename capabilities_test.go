@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestCapabilitiesForModel(t *testing.T) {
+	tests := []struct {
+		modelName string
+		want      modelCapabilities
+	}{
+		{modelName: "gpt-3.5-turbo", want: modelCapabilities{encoding: encodingCL100K, noVision: true}},
+		{modelName: "gpt-4", want: modelCapabilities{encoding: encodingCL100K, noVision: true}},
+		{modelName: "gpt-4-turbo", want: modelCapabilities{encoding: encodingCL100K}},
+		{modelName: "gpt-4.1", want: modelCapabilities{encoding: encodingO200K}},
+		{modelName: "gpt-4.1-mini", want: modelCapabilities{encoding: encodingO200K}},
+		{modelName: "gpt-4o", want: modelCapabilities{encoding: encodingO200K}},
+		{modelName: "gpt-5", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+		{modelName: "gpt-5.1", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+		{modelName: "o1-preview", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K, noVision: true}},
+		{modelName: "o3-mini", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K, noVision: true}},
+		{modelName: "o4-mini", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+		{modelName: "openai/gpt-5-mini", want: modelCapabilities{maxCompletionTokens: true, encoding: encodingO200K}},
+		{modelName: "llama3.2", want: modelCapabilities{encoding: encodingCL100K}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelName, func(t *testing.T) {
+			if got := capabilitiesForModel(tt.modelName); got != tt.want {
+				t.Errorf("capabilitiesForModel(%q) = %+v, want %+v", tt.modelName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_MaxTokensField(t *testing.T) {
+	tests := []struct {
+		modelName               string
+		wantMaxTokens           int
+		wantMaxCompletionTokens int
+	}{
+		{modelName: "gpt-4.1", wantMaxTokens: 256},
+		{modelName: "gpt-5", wantMaxCompletionTokens: 256},
+		{modelName: "o4-mini", wantMaxCompletionTokens: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelName, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: tt.modelName}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: 256},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if got.MaxCompletionTokens != tt.wantMaxCompletionTokens {
+				t.Errorf("MaxCompletionTokens = %d, want %d", got.MaxCompletionTokens, tt.wantMaxCompletionTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// extraBodyKey is the context key under which extra JSON body fields travel
+// from GenerateContent to chatBodyDoer.
+type extraBodyKey struct{}
+
+// contextWithExtraBody returns ctx carrying fields to be merged into the JSON
+// body of requests sent with it.
+func contextWithExtraBody(ctx context.Context, fields map[string]any) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, extraBodyKey{}, fields)
+}
+
+// requestExtraBodyKey is the context key for the extra body fields set with
+// ContextWithExtraBody.
+type requestExtraBodyKey struct{}
+
+// ContextWithExtraBody returns a copy of ctx whose chat completion requests
+// carry fields as extra top-level JSON body fields. They override
+// OpenAIModel.ExtraBody key by key, but never a field the request already sets.
+func ContextWithExtraBody(ctx context.Context, fields map[string]any) context.Context {
+	return context.WithValue(ctx, requestExtraBodyKey{}, fields)
+}
+
+// extraBody collects the fields go-openai cannot express that should be sent
+// alongside req.
+func (o *OpenAIModel) extraBody(ctx context.Context, req *model.LLMRequest) map[string]any {
+	fields := map[string]any{}
+	for key, value := range o.ExtraBody {
+		fields[key] = value
+	}
+	cfg := o.generationConfig(req)
+	if o.Profile == ProfileOpenRouter {
+		for key, value := range openRouterRoutingHints(cfg.RoutingConfig) {
+			fields[key] = value
+		}
+	}
+	if o.ForwardTopK && cfg.TopK != nil {
+		fields["top_k"] = int(*cfg.TopK)
+	}
+	requestFields, _ := ctx.Value(requestExtraBodyKey{}).(map[string]any)
+	for key, value := range requestFields {
+		fields[key] = value
+	}
+	return fields
+}
+
+// chatBodyDoer rewrites chat completion JSON bodies before handing requests to
+// the wrapped doer. It merges the fields carried by the request context,
+// leaving fields already present in the body untouched.
+type chatBodyDoer struct {
+	doer openai.HTTPDoer
+}
+
+func (d chatBodyDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return d.doer.Do(req)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	fields, _ := req.Context().Value(extraBodyKey{}).(map[string]any)
+	for key, value := range fields {
+		if _, ok := body[key]; ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		body[key] = raw
+	}
+	if data, err = json.Marshal(body); err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return d.doer.Do(req)
+}
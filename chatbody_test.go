@@ -0,0 +1,113 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// generate runs a non-streaming GenerateContent call and fails t on error.
+func generate(t *testing.T, ctx context.Context, m *OpenAIModel, cfg *genai.GenerateContentConfig) {
+	t.Helper()
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   cfg,
+	}
+	for _, err := range m.GenerateContent(ctx, req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+	}
+}
+
+func TestGenerateContent_ExtraBody(t *testing.T) {
+	s, m := newFakeServer(t)
+	m.ExtraBody = map[string]any{
+		"model":            "ignored",
+		"prompt_cache_key": "model-key",
+		"beta_flag":        true,
+	}
+	ctx := ContextWithExtraBody(context.Background(), map[string]any{"prompt_cache_key": "request-key"})
+
+	generate(t, ctx, m, &genai.GenerateContentConfig{})
+	var body map[string]any
+	s.lastBody(t, &body)
+
+	want := map[string]any{
+		"model":            "gpt-4o",
+		"prompt_cache_key": "request-key",
+		"beta_flag":        true,
+	}
+	for key, value := range want {
+		if diff := cmp.Diff(value, body[key]); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", key, diff)
+		}
+	}
+}
+
+func TestGenerateContent_StopSequences(t *testing.T) {
+	tests := []struct {
+		name string
+		stop []string
+		want any
+	}{
+		{name: "none", stop: nil, want: nil},
+		{name: "single", stop: []string{"END"}, want: []any{"END"}},
+		{name: "several", stop: []string{"END", "STOP"}, want: []any{"END", "STOP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			cfg := &genai.GenerateContentConfig{StopSequences: tt.stop}
+			generate(t, context.Background(), m, cfg)
+			var body map[string]any
+			s.lastBody(t, &body)
+
+			if diff := cmp.Diff(tt.want, body["stop"]); diff != "" {
+				t.Errorf("stop mismatch (-want +got):\n%s", diff)
+			}
+
+			// BuildRequest shows the stop sequences exactly as they are sent
+			built, err := m.BuildRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+				Config:   cfg,
+			})
+			if err != nil {
+				t.Fatalf("BuildRequest() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.stop, built.Stop); diff != "" {
+				t.Errorf("built stop mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateContent_ForwardTopK(t *testing.T) {
+	tests := []struct {
+		name    string
+		forward bool
+		want    any
+	}{
+		{name: "dropped by default", forward: false, want: nil},
+		{name: "forwarded", forward: true, want: 40.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			m.ModelName = "llama3"
+			m.ForwardTopK = tt.forward
+
+			generate(t, context.Background(), m, &genai.GenerateContentConfig{TopK: genai.Ptr[float32](40)})
+			var body map[string]any
+			s.lastBody(t, &body)
+
+			if diff := cmp.Diff(tt.want, body["top_k"]); diff != "" {
+				t.Errorf("top_k mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// codeExecutionText renders an ExecutableCode part as a fenced code block and
+// a CodeExecutionResult part as labeled output, so code execution turns
+// survive as readable text in the history sent to OpenAI. It returns "" for
+// other parts.
+func codeExecutionText(part *genai.Part) string {
+	switch {
+	case part.ExecutableCode != nil:
+		lang := ""
+		if part.ExecutableCode.Language != genai.LanguageUnspecified {
+			lang = strings.ToLower(string(part.ExecutableCode.Language))
+		}
+		return fmt.Sprintf("```%s\n%s\n```", lang, strings.TrimRight(part.ExecutableCode.Code, "\n"))
+	case part.CodeExecutionResult != nil:
+		label := "Code execution output"
+		switch part.CodeExecutionResult.Outcome {
+		case genai.OutcomeFailed:
+			label = "Code execution failed"
+		case genai.OutcomeDeadlineExceeded:
+			label = "Code execution timed out"
+		}
+		output := strings.TrimRight(part.CodeExecutionResult.Output, "\n")
+		if output == "" {
+			return label + "."
+		}
+		return fmt.Sprintf("%s:\n```\n%s\n```", label, output)
+	}
+	return ""
+}
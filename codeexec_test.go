@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionMessage_CodeExecution(t *testing.T) {
+	m := &OpenAIModel{}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "model",
+		Parts: []*genai.Part{
+			{Text: "Let me compute that."},
+			{ExecutableCode: &genai.ExecutableCode{Language: genai.LanguagePython, Code: "print(6 * 7)\n"}},
+			{CodeExecutionResult: &genai.CodeExecutionResult{Outcome: genai.OutcomeOK, Output: "42\n"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+
+	want := []openai.ChatMessagePart{
+		{Type: openai.ChatMessagePartTypeText, Text: "Let me compute that."},
+		{Type: openai.ChatMessagePartTypeText, Text: "```python\nprint(6 * 7)\n```"},
+		{Type: openai.ChatMessagePartTypeText, Text: "Code execution output:\n```\n42\n```"},
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	if diff := cmp.Diff(want, msgs[0].MultiContent); diff != "" {
+		t.Errorf("MultiContent mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCodeExecutionText(t *testing.T) {
+	tests := []struct {
+		name string
+		part *genai.Part
+		want string
+	}{
+		{
+			name: "code without language",
+			part: &genai.Part{ExecutableCode: &genai.ExecutableCode{Code: "x = 1"}},
+			want: "```\nx = 1\n```",
+		},
+		{
+			name: "failed execution",
+			part: &genai.Part{CodeExecutionResult: &genai.CodeExecutionResult{Outcome: genai.OutcomeFailed, Output: "NameError: y"}},
+			want: "Code execution failed:\n```\nNameError: y\n```",
+		},
+		{
+			name: "timeout without output",
+			part: &genai.Part{CodeExecutionResult: &genai.CodeExecutionResult{Outcome: genai.OutcomeDeadlineExceeded}},
+			want: "Code execution timed out.",
+		},
+		{name: "other part", part: &genai.Part{Text: "hi"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codeExecutionText(tt.part); got != tt.want {
+				t.Errorf("codeExecutionText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
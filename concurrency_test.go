@@ -0,0 +1,92 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// TestGenerateContent_Concurrent runs streaming and non-streaming calls in
+// parallel on one fully configured model. Run with -race to detect data races.
+func TestGenerateContent_Concurrent(t *testing.T) {
+	// The server echoes the last message back, so that answers can be matched
+	// to their calls.
+	s, m := newFakeServer(t)
+	s.handle("/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		text := req.Messages[len(req.Messages)-1].Content
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{
+					Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: text},
+					FinishReason: openai.FinishReasonStop,
+				}},
+				Usage: openai.Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: text[:1]}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: text[1:]}, openai.FinishReasonStop),
+		} {
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	var requests, responses, firstTokens atomic.Int64
+	m.DefaultConfig = &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.2)}
+	m.ExtraBody = map[string]any{"prompt_cache_key": "shared"}
+	m.LogitBias = map[string]int{"42": 5}
+	m.Metadata = map[string]string{"team": "search"}
+	m.OnRequest = func(context.Context, *openai.ChatCompletionRequest) { requests.Add(1) }
+	m.OnResponse = func(context.Context, *model.LLMResponse, error) { responses.Add(1) }
+	m.OnFirstToken = func(time.Duration) { firstTokens.Add(1) }
+
+	const calls = 32
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			want := fmt.Sprintf("reply %d", i)
+			req := &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: want}}}},
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: int32(i + 1)},
+			}
+			stream := i%2 == 0
+			var final *model.LLMResponse
+			for resp, err := range m.GenerateContent(context.Background(), req, stream) {
+				if err != nil {
+					t.Errorf("GenerateContent(stream=%v) error = %v", stream, err)
+					return
+				}
+				final = resp
+			}
+			if got := final.Content.Parts[0].Text; got != want {
+				t.Errorf("GenerateContent(stream=%v) text = %q, want %q", stream, got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests.Load() != calls || responses.Load() != calls || firstTokens.Load() != calls/2 {
+		t.Errorf("hooks saw %d requests, %d responses and %d first tokens, want %d, %d and %d",
+			requests.Load(), responses.Load(), firstTokens.Load(), calls, calls, calls/2)
+	}
+}
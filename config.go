@@ -0,0 +1,39 @@
+package openai
+
+import (
+	"reflect"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// generationConfig returns the generation settings for req, with
+// o.DefaultConfig filling in every field the request leaves unset. The result
+// is never nil and must not be modified.
+func (o *OpenAIModel) generationConfig(req *model.LLMRequest) *genai.GenerateContentConfig {
+	return mergeConfig(o.DefaultConfig, req.Config)
+}
+
+// mergeConfig returns a config holding the non-zero fields of cfg and, for the
+// remaining fields, the values of defaults. Fields are merged shallowly, so a
+// set slice or pointer in cfg replaces the default as a whole.
+func mergeConfig(defaults, cfg *genai.GenerateContentConfig) *genai.GenerateContentConfig {
+	switch {
+	case defaults == nil && cfg == nil:
+		return &genai.GenerateContentConfig{}
+	case defaults == nil:
+		return cfg
+	case cfg == nil:
+		return defaults
+	}
+
+	merged := *defaults
+	src := reflect.ValueOf(cfg).Elem()
+	dst := reflect.ValueOf(&merged).Elem()
+	for i := range src.NumField() {
+		if field := src.Field(i); !field.IsZero() {
+			dst.Field(i).Set(field)
+		}
+	}
+	return &merged
+}
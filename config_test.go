@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_DefaultConfig(t *testing.T) {
+	defaultTemp := float32(0.2)
+	requestTemp := float32(0.9)
+	contents := []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}}
+
+	m := &OpenAIModel{
+		ModelName: "gpt-4o",
+		DefaultConfig: &genai.GenerateContentConfig{
+			Temperature:     &defaultTemp,
+			MaxOutputTokens: 512,
+			StopSequences:   []string{"END"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		cfg           *genai.GenerateContentConfig
+		wantTemp      float32
+		wantMaxTokens int
+		wantStop      []string
+	}{
+		{
+			name:          "nil request config uses defaults",
+			cfg:           nil,
+			wantTemp:      0.2,
+			wantMaxTokens: 512,
+			wantStop:      []string{"END"},
+		},
+		{
+			name:          "unset request fields use defaults",
+			cfg:           &genai.GenerateContentConfig{},
+			wantTemp:      0.2,
+			wantMaxTokens: 512,
+			wantStop:      []string{"END"},
+		},
+		{
+			name: "request values override defaults",
+			cfg: &genai.GenerateContentConfig{
+				Temperature:     &requestTemp,
+				MaxOutputTokens: 64,
+			},
+			wantTemp:      0.9,
+			wantMaxTokens: 64,
+			wantStop:      []string{"END"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{Contents: contents, Config: tt.cfg})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.Temperature != tt.wantTemp {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemp)
+			}
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %v, want %v", got.MaxTokens, tt.wantMaxTokens)
+			}
+			if len(got.Stop) != len(tt.wantStop) || (len(got.Stop) > 0 && got.Stop[0] != tt.wantStop[0]) {
+				t.Errorf("Stop = %v, want %v", got.Stop, tt.wantStop)
+			}
+		})
+	}
+
+	if *m.DefaultConfig.Temperature != defaultTemp || m.DefaultConfig.MaxOutputTokens != 512 {
+		t.Errorf("DefaultConfig was modified: %+v", m.DefaultConfig)
+	}
+}
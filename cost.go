@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+)
+
+// ModelPrice is what a model charges, in US dollars per million tokens.
+type ModelPrice struct {
+	Input float64
+
+	// CachedInput applies to prompt tokens served from the prompt cache.
+	// Zero bills them at Input.
+	CachedInput float64
+
+	Output float64
+
+	// Reasoning applies to hidden reasoning tokens. Zero bills them at
+	// Output, as OpenAI does.
+	Reasoning float64
+}
+
+// DefaultPrices holds OpenAI list prices by model name prefix. Prices change
+// over time, so callers that depend on exact figures should set
+// OpenAIModel.Prices or replace entries here.
+var DefaultPrices = map[string]ModelPrice{
+	"gpt-5":        {Input: 1.25, CachedInput: 0.125, Output: 10},
+	"gpt-5-mini":   {Input: 0.25, CachedInput: 0.025, Output: 2},
+	"gpt-5-nano":   {Input: 0.05, CachedInput: 0.005, Output: 0.40},
+	"gpt-4.1":      {Input: 2, CachedInput: 0.50, Output: 8},
+	"gpt-4.1-mini": {Input: 0.40, CachedInput: 0.10, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, CachedInput: 0.025, Output: 0.40},
+	"gpt-4o":       {Input: 2.50, CachedInput: 1.25, Output: 10},
+	"gpt-4o-mini":  {Input: 0.15, CachedInput: 0.075, Output: 0.60},
+	"o1":           {Input: 15, CachedInput: 7.50, Output: 60},
+	"o3":           {Input: 2, CachedInput: 0.50, Output: 8},
+	"o3-mini":      {Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	"o4-mini":      {Input: 1.10, CachedInput: 0.275, Output: 4.40},
+}
+
+// ErrNoUsage is returned by EstimateCost for a response without usage
+// metadata, such as a partial streaming response.
+var ErrNoUsage = errors.New("response has no usage metadata")
+
+// EstimateCost returns the cost in US dollars of resp from its usage metadata.
+// The price is looked up by the model version the backend reported, falling
+// back to ModelName, in Prices and then DefaultPrices, where the longest
+// matching name prefix wins.
+func (o *OpenAIModel) EstimateCost(resp *model.LLMResponse) (float64, error) {
+	if resp == nil || resp.UsageMetadata == nil {
+		return 0, ErrNoUsage
+	}
+	name, _ := resp.CustomMetadata[ModelVersionMetadataKey].(string)
+	if name == "" {
+		name = o.ModelName
+	}
+	price, ok := lookupPrice(o.Prices, name)
+	if !ok {
+		price, ok = lookupPrice(DefaultPrices, name)
+	}
+	if !ok {
+		return 0, fmt.Errorf("no price known for model %q", name)
+	}
+
+	usage := resp.UsageMetadata
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	reasoningPrice := price.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = price.Output
+	}
+	// OpenAI counts cached tokens as prompt tokens and reasoning tokens as
+	// completion tokens
+	cost := float64(usage.PromptTokenCount-usage.CachedContentTokenCount)*price.Input +
+		float64(usage.CachedContentTokenCount)*cachedPrice +
+		float64(usage.CandidatesTokenCount-usage.ThoughtsTokenCount)*price.Output +
+		float64(usage.ThoughtsTokenCount)*reasoningPrice
+	return cost / 1e6, nil
+}
+
+// lookupPrice returns the price in prices whose key is the longest prefix of
+// name.
+func lookupPrice(prices map[string]ModelPrice, name string) (ModelPrice, bool) {
+	name = strings.ToLower(name)
+	var best string
+	var found bool
+	for prefix := range prices {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) && (!found || len(prefix) > len(best)) {
+			best, found = prefix, true
+		}
+	}
+	return prices[best], found
+}
@@ -0,0 +1,73 @@
+package openai
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestEstimateCost(t *testing.T) {
+	// 600 uncached and 400 cached prompt tokens, 300 visible and 200
+	// reasoning completion tokens
+	usage := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        1000,
+		CachedContentTokenCount: 400,
+		CandidatesTokenCount:    500,
+		ThoughtsTokenCount:      200,
+	}
+
+	tests := []struct {
+		name   string
+		model  *OpenAIModel
+		resp   *model.LLMResponse
+		want   float64
+		hasErr bool
+	}{
+		{
+			name:  "configured prices",
+			model: &OpenAIModel{ModelName: "my-model", Prices: map[string]ModelPrice{"my-model": {Input: 2, CachedInput: 1, Output: 8, Reasoning: 10}}},
+			resp:  &model.LLMResponse{UsageMetadata: usage},
+			want:  (600*2 + 400*1 + 300*8 + 200*10) / 1e6,
+		},
+		{
+			name:  "default prices by model version",
+			model: &OpenAIModel{ModelName: "o4"},
+			resp: &model.LLMResponse{
+				UsageMetadata:  usage,
+				CustomMetadata: map[string]any{ModelVersionMetadataKey: "o4-mini-2025-04-16"},
+			},
+			want: (600*1.10 + 400*0.275 + 500*4.40) / 1e6,
+		},
+		{
+			name:  "longest prefix wins",
+			model: &OpenAIModel{ModelName: "gpt-4o-mini"},
+			resp:  &model.LLMResponse{UsageMetadata: usage},
+			want:  (600*0.15 + 400*0.075 + 500*0.60) / 1e6,
+		},
+		{
+			name:   "unknown model",
+			model:  &OpenAIModel{ModelName: "llama-3"},
+			resp:   &model.LLMResponse{UsageMetadata: usage},
+			hasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.model.EstimateCost(tt.resp)
+			if (err != nil) != tt.hasErr {
+				t.Fatalf("EstimateCost() error = %v, wantErr %v", err, tt.hasErr)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("EstimateCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&OpenAIModel{ModelName: "gpt-4o"}).EstimateCost(&model.LLMResponse{}); !errors.Is(err, ErrNoUsage) {
+		t.Errorf("EstimateCost() error = %v, want ErrNoUsage", err)
+	}
+}
@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxEmbeddingInputs is the number of inputs the embeddings endpoint accepts
+// in a single request.
+const maxEmbeddingInputs = 2048
+
+// OpenAIEmbedder computes text embeddings through the OpenAI embeddings
+// endpoint, sharing client configuration with OpenAIModel.
+type OpenAIEmbedder struct {
+	Client    *openai.Client
+	ModelName string
+
+	// Dimensions requests embeddings of the given size. Zero uses the model's
+	// default. Only text-embedding-3 and later models support it.
+	Dimensions int
+
+	// BatchSize caps the number of inputs sent per request. Zero or values above
+	// the API limit use the limit of 2048.
+	BatchSize int
+}
+
+func NewOpenAIEmbedderWithAPIKey(modelName string, apiKey string) *OpenAIEmbedder {
+	cfg := openai.DefaultConfig(apiKey)
+	return NewOpenAIEmbedder(modelName, cfg)
+}
+
+func NewOpenAIEmbedder(modelName string, cfg openai.ClientConfig) *OpenAIEmbedder {
+	client := openai.NewClientWithConfig(cfg)
+	return &OpenAIEmbedder{
+		Client:    client,
+		ModelName: modelName,
+	}
+}
+
+// Embed returns one embedding per input, in input order. Inputs beyond the
+// batch size are split across several requests.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	batchSize := e.BatchSize
+	if batchSize <= 0 || batchSize > maxEmbeddingInputs {
+		batchSize = maxEmbeddingInputs
+	}
+
+	embeddings := make([][]float32, 0, len(inputs))
+	for start := 0; start < len(inputs); start += batchSize {
+		end := min(start+batchSize, len(inputs))
+		batch, err := e.embedBatch(ctx, inputs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+func (e *OpenAIEmbedder) embedBatch(ctx context.Context, inputs []string) ([][]float32, error) {
+	resp, err := e.Client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input:      inputs,
+		Model:      openai.EmbeddingModel(e.ModelName),
+		Dimensions: e.Dimensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(inputs) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(resp.Data), len(inputs))
+	}
+
+	// The API reports each embedding's input position, which is not guaranteed
+	// to match the order of Data.
+	embeddings := make([][]float32, len(inputs))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(inputs) || embeddings[data.Index] != nil {
+			return nil, fmt.Errorf("invalid embedding index %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+	return embeddings, nil
+}
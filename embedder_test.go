@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+)
+
+// newFakeEmbeddingsServer returns an embedder whose server embeds each input
+// as [len(input), position in request].
+func newFakeEmbeddingsServer(t *testing.T) (*fakeServer, *OpenAIEmbedder) {
+	t.Helper()
+	s, _ := newFakeServer(t)
+	s.handle("/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		resp := openai.EmbeddingResponse{Model: openai.EmbeddingModel(req.Model)}
+		// Return the embeddings in reverse to exercise reordering by index.
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, openai.Embedding{
+				Object:    "embedding",
+				Index:     i,
+				Embedding: []float32{float32(len(req.Input[i])), float32(i)},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	return s, NewOpenAIEmbedder("text-embedding-3-small", s.config())
+}
+
+// embeddingBatches returns the number of inputs in each embeddings request s
+// received.
+func embeddingBatches(t *testing.T, s *fakeServer) []int {
+	t.Helper()
+	var batches []int
+	for _, req := range s.requestsTo("/embeddings") {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		req.decode(t, &body)
+		batches = append(batches, len(body.Input))
+	}
+	return batches
+}
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	s, embedder := newFakeEmbeddingsServer(t)
+
+	got, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	want := [][]float32{{1, 0}, {2, 1}, {3, 2}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Embed() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{3}, embeddingBatches(t, s)); diff != "" {
+		t.Errorf("batch sizes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenAIEmbedder_EmbedBatching(t *testing.T) {
+	s, embedder := newFakeEmbeddingsServer(t)
+	embedder.BatchSize = 2
+
+	got, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	want := [][]float32{{1, 0}, {2, 1}, {3, 0}, {4, 1}, {5, 0}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Embed() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 2, 1}, embeddingBatches(t, s)); diff != "" {
+		t.Errorf("batch sizes mismatch (-want +got):\n%s", diff)
+	}
+}
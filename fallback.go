@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"context"
+	"iter"
+
+	"google.golang.org/adk/model"
+)
+
+var _ model.LLM = &FallbackModel{}
+
+// FallbackModel implements model.LLM over an ordered list of models, such as
+// OpenAIModels for a primary and a backup deployment. A call goes to the
+// first model and moves on to the next when it fails with a retryable error:
+// a rate limit (429), a server error or a dropped connection. Other errors
+// are returned as is.
+//
+// When streaming, a call only falls back before the model yielded its first
+// response. Once output has been delivered, errors are returned, so no output
+// is replayed by another model.
+type FallbackModel struct {
+	Models []model.LLM
+}
+
+// NewFallbackModel returns a FallbackModel trying models in order.
+func NewFallbackModel(models ...model.LLM) *FallbackModel {
+	return &FallbackModel{Models: models}
+}
+
+// Name implements model.LLM. It returns the name of the first model.
+func (f *FallbackModel) Name() string {
+	if len(f.Models) == 0 {
+		return ""
+	}
+	return f.Models[0].Name()
+}
+
+// GenerateContent implements model.LLM.
+func (f *FallbackModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for i, m := range f.Models {
+			last := i == len(f.Models)-1
+			delivered := false
+			fallBack := false
+			for resp, err := range m.GenerateContent(ctx, req, stream) {
+				if err != nil && !delivered && !last && isRetryableError(err) {
+					fallBack = true
+					break
+				}
+				delivered = true
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if !fallBack {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// fakeLLM yields resps followed by err, if set, and counts its calls.
+type fakeLLM struct {
+	name  string
+	resps []*model.LLMResponse
+	err   error
+	calls int
+}
+
+func (f *fakeLLM) Name() string { return f.name }
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	f.calls++
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for _, resp := range f.resps {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if f.err != nil {
+			yield(nil, f.err)
+		}
+	}
+}
+
+func textResponse(text string, partial bool) *model.LLMResponse {
+	return &model.LLMResponse{
+		Content: &genai.Content{Role: "model", Parts: []*genai.Part{{Text: text}}},
+		Partial: partial,
+	}
+}
+
+func TestFallbackModel(t *testing.T) {
+	rateLimited := &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Message: "rate limited"}
+	badRequest := &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "bad request"}
+
+	tests := []struct {
+		name      string
+		primary   *fakeLLM
+		secondary *fakeLLM
+		wantTexts []string
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "falls back on rate limit",
+			primary:   &fakeLLM{name: "primary", err: rateLimited},
+			secondary: &fakeLLM{name: "secondary", resps: []*model.LLMResponse{textResponse("Hel", true), textResponse("Hello", false)}},
+			wantTexts: []string{"Hel", "Hello"},
+			wantCalls: 1,
+		},
+		{
+			name:      "primary succeeds",
+			primary:   &fakeLLM{name: "primary", resps: []*model.LLMResponse{textResponse("Hi", false)}},
+			secondary: &fakeLLM{name: "secondary"},
+			wantTexts: []string{"Hi"},
+			wantCalls: 0,
+		},
+		{
+			name:      "non-retryable error",
+			primary:   &fakeLLM{name: "primary", err: badRequest},
+			secondary: &fakeLLM{name: "secondary"},
+			wantErr:   badRequest,
+			wantCalls: 0,
+		},
+		{
+			name:      "no fallback after first token",
+			primary:   &fakeLLM{name: "primary", resps: []*model.LLMResponse{textResponse("Hel", true)}, err: rateLimited},
+			secondary: &fakeLLM{name: "secondary"},
+			wantTexts: []string{"Hel"},
+			wantErr:   rateLimited,
+			wantCalls: 0,
+		},
+		{
+			name:      "last model error is returned",
+			primary:   &fakeLLM{name: "primary", err: rateLimited},
+			secondary: &fakeLLM{name: "secondary", err: rateLimited},
+			wantErr:   rateLimited,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFallbackModel(tt.primary, tt.secondary)
+			if got := f.Name(); got != "primary" {
+				t.Errorf("Name() = %q, want %q", got, "primary")
+			}
+
+			var texts []string
+			var gotErr error
+			for resp, err := range f.GenerateContent(context.Background(), &model.LLMRequest{}, true) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				texts = append(texts, resp.Content.Parts[0].Text)
+			}
+
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("GenerateContent() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantTexts, texts); diff != "" {
+				t.Errorf("texts mismatch (-want +got):\n%s", diff)
+			}
+			if tt.secondary.calls != tt.wantCalls {
+				t.Errorf("secondary called %d times, want %d", tt.secondary.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// firstTokenStream reports through onFirstToken how long after start the
+// first chunk carrying content arrived.
+type firstTokenStream struct {
+	chatCompletionStream
+	start        time.Time
+	onFirstToken func(time.Duration)
+	reported     bool
+}
+
+// observeFirstToken wraps stream so that OnFirstToken fires once, timed from
+// start. It returns stream unchanged when OnFirstToken is unset.
+func (o *OpenAIModel) observeFirstToken(stream chatCompletionStream, start time.Time) chatCompletionStream {
+	if o.OnFirstToken == nil {
+		return stream
+	}
+	return &firstTokenStream{chatCompletionStream: stream, start: start, onFirstToken: o.OnFirstToken}
+}
+
+func (s *firstTokenStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	chunk, err := s.chatCompletionStream.Recv()
+	if err == nil && !s.reported && hasDelta(chunk) {
+		s.reported = true
+		s.onFirstToken(time.Since(s.start))
+	}
+	return chunk, err
+}
+
+// hasDelta reports whether chunk carries text, reasoning, a refusal or a tool
+// call.
+func hasDelta(chunk openai.ChatCompletionStreamResponse) bool {
+	for _, choice := range chunk.Choices {
+		delta := choice.Delta
+		if delta.Content != "" || delta.ReasoningContent != "" || delta.Refusal != "" || len(delta.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
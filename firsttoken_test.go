@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// delayedStream waits delay before returning the first chunk of stream.
+type delayedStream struct {
+	*fakeStream
+	delay   time.Duration
+	delayed bool
+}
+
+func (s *delayedStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	if !s.delayed {
+		s.delayed = true
+		time.Sleep(s.delay)
+	}
+	return s.fakeStream.Recv()
+}
+
+func TestObserveFirstToken(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	stream := &delayedStream{
+		fakeStream: &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: " world"}, openai.FinishReasonStop),
+		}},
+		delay: delay,
+	}
+
+	var calls []time.Duration
+	m := &OpenAIModel{OnFirstToken: func(d time.Duration) { calls = append(calls, d) }}
+	start := time.Now()
+	resps := collectStream(t, m, m.observeFirstToken(stream, start))
+
+	if len(calls) != 1 {
+		t.Fatalf("OnFirstToken called %d times, want 1", len(calls))
+	}
+	if calls[0] < delay || calls[0] > time.Since(start) {
+		t.Errorf("OnFirstToken duration = %v, want between %v and %v", calls[0], delay, time.Since(start))
+	}
+	if got := resps[len(resps)-1].Content.Parts[0].Text; got != "Hello world" {
+		t.Errorf("final text = %q, want %q", got, "Hello world")
+	}
+	if !stream.closed {
+		t.Error("stream was not closed")
+	}
+}
+
+func TestObserveFirstToken_Unset(t *testing.T) {
+	stream := &fakeStream{}
+	if got := (&OpenAIModel{}).observeFirstToken(stream, time.Now()); got != chatCompletionStream(stream) {
+		t.Errorf("observeFirstToken() = %T, want the stream unchanged", got)
+	}
+}
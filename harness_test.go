@@ -0,0 +1,434 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// fakeServer is an OpenAI-compatible test server. It records every request
+// it receives and answers chat completions with response, or respond if set,
+// and streaming ones with chunks as server-sent events; both default to a
+// plain "ok" answer. Other endpoints are answered by the handlers registered
+// with handle.
+type fakeServer struct {
+	response openai.ChatCompletionResponse
+	respond  func(req openai.ChatCompletionRequest) openai.ChatCompletionResponse
+	chunks   []openai.ChatCompletionStreamResponse
+
+	// delay holds back every chat completion before its headers are sent and
+	// chunkDelay separates consecutive stream chunks. While a stream waits out
+	// chunkDelay, a keepalive comment is sent every heartbeat, if set.
+	delay      time.Duration
+	chunkDelay time.Duration
+	heartbeat  time.Duration
+
+	// failures fails the first chat completions. A failing stream sends
+	// failChunks and then drops the connection; without failChunks, the
+	// request is answered with 503.
+	failures   int
+	failChunks []openai.ChatCompletionStreamResponse
+
+	url string
+
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	requests []fakeRequest
+}
+
+// fakeRequest is a request received by a fakeServer.
+type fakeRequest struct {
+	url    *url.URL
+	header http.Header
+	body   []byte
+}
+
+// decode unmarshals the JSON body of r into v.
+func (r fakeRequest) decode(t *testing.T, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("failed to decode request to %s: %v", r.url.Path, err)
+	}
+}
+
+// newFakeServer starts a fakeServer and returns it with a model pointed at it
+// through NewOpenAIModel's base URL.
+func newFakeServer(t *testing.T) (*fakeServer, *OpenAIModel) {
+	t.Helper()
+	s := &fakeServer{
+		response: openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "ok"},
+			FinishReason: openai.FinishReasonStop,
+		}}},
+		chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant, Content: "ok"}, openai.FinishReasonStop),
+		},
+		handlers: map[string]http.HandlerFunc{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, fakeRequest{url: r.URL, header: r.Header.Clone(), body: body})
+		handler, ok := s.handlers[r.URL.Path]
+		s.mu.Unlock()
+		switch {
+		case ok:
+			handler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/chat/completions"):
+			s.serveChat(t, w, r, body)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	s.url = server.URL
+	return s, NewOpenAIModel("gpt-4o", s.config())
+}
+
+// config returns a client configuration pointed at the server.
+func (s *fakeServer) config() openai.ClientConfig {
+	cfg := openai.DefaultConfig("test")
+	cfg.BaseURL = s.url
+	return cfg
+}
+
+// handle answers requests to path with handler instead of the defaults.
+func (s *fakeServer) handle(path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+// requestsTo returns the requests received on paths ending in suffix.
+func (s *fakeServer) requestsTo(suffix string) []fakeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var reqs []fakeRequest
+	for _, req := range s.requests {
+		if strings.HasSuffix(req.url.Path, suffix) {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// lastRequest returns the most recent chat completion request the server
+// received.
+func (s *fakeServer) lastRequest(t *testing.T) openai.ChatCompletionRequest {
+	t.Helper()
+	var req openai.ChatCompletionRequest
+	s.lastBody(t, &req)
+	return req
+}
+
+// lastBody decodes the body of the most recent chat completion request into
+// v, keeping fields the go-openai types do not know about when v is a map.
+func (s *fakeServer) lastBody(t *testing.T, v any) {
+	t.Helper()
+	reqs := s.requestsTo("/chat/completions")
+	if len(reqs) == 0 {
+		t.Fatal("server received no chat completion requests")
+	}
+	reqs[len(reqs)-1].decode(t, v)
+}
+
+// serveChat answers a chat completion request whose body is body.
+func (s *fakeServer) serveChat(t *testing.T, w http.ResponseWriter, r *http.Request, body []byte) {
+	// Only Stream is decoded up front, as some tests send bodies the
+	// go-openai request type cannot hold.
+	var stream struct {
+		Stream bool `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &stream); err != nil {
+		t.Errorf("failed to decode request: %v", err)
+	}
+	failing := len(s.requestsTo("/chat/completions")) <= s.failures
+	if !holdResponse(w, r, s.delay, 0) {
+		return
+	}
+	if failing && len(s.failChunks) == 0 {
+		http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	if !stream.Stream {
+		resp := s.response
+		if s.respond != nil {
+			var req openai.ChatCompletionRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			resp = s.respond(req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	chunks := s.chunks
+	if failing {
+		chunks = s.failChunks
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.(http.Flusher).Flush()
+	for i, chunk := range chunks {
+		if i > 0 && !holdResponse(w, r, s.chunkDelay, s.heartbeat) {
+			return
+		}
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			t.Errorf("failed to marshal chunk: %v", err)
+		}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		w.(http.Flusher).Flush()
+	}
+	if failing {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+// holdResponse pauses a response for d, sending a keepalive comment every
+// heartbeat if it is set. It reports false if the client went away meanwhile.
+func holdResponse(w http.ResponseWriter, r *http.Request, d, heartbeat time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	deadline := time.After(d)
+	var ticks <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	for {
+		select {
+		case <-ticks:
+			fmt.Fprint(w, ": keepalive\n\n")
+			w.(http.Flusher).Flush()
+		case <-deadline:
+			return true
+		case <-r.Context().Done():
+			return false
+		}
+	}
+}
+
+// weatherRequest asks for the weather with a get_weather tool available,
+// following up on an earlier call when history is set.
+func weatherRequest(history ...*genai.Content) *model.LLMRequest {
+	contents := append([]*genai.Content{
+		{Role: "user", Parts: []*genai.Part{{Text: "What's the weather in Paris?"}}},
+	}, history...)
+	return &model.LLMRequest{
+		Contents: contents,
+		Config: &genai.GenerateContentConfig{
+			Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:        "get_weather",
+				Description: "Returns the weather for a location",
+				Parameters: &genai.Schema{
+					Type:       genai.TypeObject,
+					Properties: map[string]*genai.Schema{"location": {Type: genai.TypeString}},
+					Required:   []string{"location"},
+				},
+			}}}},
+		},
+	}
+}
+
+var weatherCall = &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"location": "Paris"}}
+
+func TestHarness_Generate(t *testing.T) {
+	server, m := newFakeServer(t)
+	server.response = openai.ChatCompletionResponse{
+		Model: "gpt-4o-2024-08-06",
+		Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleAssistant,
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_1",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+				}},
+			},
+			FinishReason: openai.FinishReasonToolCalls,
+		}},
+		Usage: openai.Usage{PromptTokens: 20, CompletionTokens: 8, TotalTokens: 28},
+	}
+
+	var resps []*model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resps = append(resps, resp)
+	}
+	if len(resps) != 1 {
+		t.Fatalf("got %d responses, want 1", len(resps))
+	}
+	resp := resps[0]
+	if diff := cmp.Diff([]*genai.Part{{FunctionCall: weatherCall}}, resp.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+	if !resp.TurnComplete || resp.Partial {
+		t.Errorf("TurnComplete = %v, Partial = %v, want a complete turn", resp.TurnComplete, resp.Partial)
+	}
+	wantUsage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 20, CandidatesTokenCount: 8, TotalTokenCount: 28}
+	if diff := cmp.Diff(wantUsage, resp.UsageMetadata); diff != "" {
+		t.Errorf("usage mismatch (-want +got):\n%s", diff)
+	}
+
+	req := server.lastRequest(t)
+	if req.Stream {
+		t.Error("request Stream = true, want false")
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("request tools = %+v, want get_weather", req.Tools)
+	}
+}
+
+func TestHarness_GenerateStream(t *testing.T) {
+	server, m := newFakeServer(t)
+	m.StreamUsage = true
+	index := 0
+	toolCallChunk := func(call openai.ToolCall) openai.ChatCompletionStreamResponse {
+		call.Index = &index
+		return deltaChunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{call}}, "")
+	}
+	server.chunks = []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant, Content: "Checking."}, ""),
+		toolCallChunk(openai.ToolCall{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_weather"}}),
+		toolCallChunk(openai.ToolCall{Function: openai.FunctionCall{Arguments: `{"location":`}}),
+		toolCallChunk(openai.ToolCall{Function: openai.FunctionCall{Arguments: `"Paris"}`}}),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonToolCalls),
+		{Usage: &openai.Usage{PromptTokens: 20, CompletionTokens: 12, TotalTokens: 32}},
+	}
+
+	var resps []*model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resps = append(resps, resp)
+	}
+	if len(resps) != 2 {
+		t.Fatalf("got %d responses, want a partial and a final one", len(resps))
+	}
+	if !resps[0].Partial || resps[0].Content.Parts[0].Text != "Checking." {
+		t.Errorf("first response = %+v, want partial text", resps[0])
+	}
+
+	final := resps[1]
+	wantParts := []*genai.Part{{Text: "Checking."}, {FunctionCall: weatherCall}}
+	if diff := cmp.Diff(wantParts, final.Content.Parts); diff != "" {
+		t.Errorf("final parts mismatch (-want +got):\n%s", diff)
+	}
+	if final.Partial || !final.TurnComplete {
+		t.Errorf("Partial = %v, TurnComplete = %v, want a complete turn", final.Partial, final.TurnComplete)
+	}
+	wantUsage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 20, CandidatesTokenCount: 12, TotalTokenCount: 32}
+	if diff := cmp.Diff(wantUsage, final.UsageMetadata); diff != "" {
+		t.Errorf("usage mismatch (-want +got):\n%s", diff)
+	}
+
+	req := server.lastRequest(t)
+	if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+		t.Errorf("request Stream = %v, StreamOptions = %+v, want a stream including usage", req.Stream, req.StreamOptions)
+	}
+}
+
+func TestHarness_ToolResultRoundTrip(t *testing.T) {
+	server, m := newFakeServer(t)
+	server.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+		Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "It is sunny in Paris."},
+		FinishReason: openai.FinishReasonStop,
+	}}}
+
+	req := weatherRequest(
+		&genai.Content{Role: "model", Parts: []*genai.Part{{FunctionCall: weatherCall}}},
+		&genai.Content{Role: "user", Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       "call_1",
+			Name:     "get_weather",
+			Response: map[string]any{"forecast": "sunny"},
+		}}}},
+	)
+	for resp, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if got := resp.Content.Parts[0].Text; got != "It is sunny in Paris." {
+			t.Errorf("text = %q, want the final answer", got)
+		}
+	}
+
+	msgs := server.lastRequest(t).Messages
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want user, assistant and tool", len(msgs))
+	}
+	if got := msgs[1]; got.Role != openai.ChatMessageRoleAssistant || len(got.ToolCalls) != 1 || got.ToolCalls[0].ID != "call_1" {
+		t.Errorf("assistant message = %+v, want the get_weather call", got)
+	}
+	if got := msgs[2]; got.Role != openai.ChatMessageRoleTool || got.ToolCallID != "call_1" || got.Content != `{"forecast":"sunny"}` {
+		t.Errorf("tool message = %+v, want the call_1 result", got)
+	}
+}
+
+func TestBuildRequest_MatchesSentRequest(t *testing.T) {
+	server, m := newFakeServer(t)
+	server.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+		Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Sunny"},
+		FinishReason: openai.FinishReasonStop,
+	}}}
+	m.OnRequest = func(ctx context.Context, req *openai.ChatCompletionRequest) {
+		req.Seed = genai.Ptr(7)
+	}
+	req := weatherRequest()
+	req.Config.SystemInstruction = &genai.Content{Parts: []*genai.Part{{Text: "Be brief."}}}
+	req.Config.Temperature = genai.Ptr[float32](0.2)
+
+	built, err := m.BuildRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BuildRequest() error = %v", err)
+	}
+	if built.Seed == nil || *built.Seed != 7 {
+		t.Errorf("Seed = %v, want the OnRequest value", built.Seed)
+	}
+	for _, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+	}
+
+	want, err := json.Marshal(server.lastRequest(t))
+	if err != nil {
+		t.Fatalf("failed to marshal sent request: %v", err)
+	}
+	got, err := json.Marshal(built)
+	if err != nil {
+		t.Fatalf("failed to marshal built request: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("BuildRequest() = %s, sent %s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// WithDefaultHeaders adds headers to every request the model's client sends,
+// such as a gateway key under a custom header. A header the request already
+// carries, such as Authorization, is left as is. It composes with the
+// ClientConfig's HTTPClient: an *http.Client is copied with its transport
+// wrapped, so the caller's client is not modified.
+func WithDefaultHeaders(headers http.Header) Option {
+	return func(o *OpenAIModel) {
+		if o.defaultHeaders == nil {
+			o.defaultHeaders = http.Header{}
+		}
+		for name, values := range headers {
+			for _, value := range values {
+				o.defaultHeaders.Add(name, value)
+			}
+		}
+	}
+}
+
+// withDefaultHeaders returns doer adding headers to the requests it sends.
+func withDefaultHeaders(doer openai.HTTPDoer, headers http.Header) openai.HTTPDoer {
+	if len(headers) == 0 {
+		return doer
+	}
+	client, ok := doer.(*http.Client)
+	if !ok {
+		return headerDoer{doer: doer, headers: headers}
+	}
+	wrapped := *client
+	wrapped.Transport = headerTransport{base: client.Transport, headers: headers}
+	return &wrapped
+}
+
+// headerTransport adds headers to requests before passing them to base, or
+// http.DefaultTransport when base is nil.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(addHeaders(req, t.headers))
+}
+
+// headerDoer adds headers to requests sent through a doer that is not an
+// *http.Client.
+type headerDoer struct {
+	doer    openai.HTTPDoer
+	headers http.Header
+}
+
+func (d headerDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.doer.Do(addHeaders(req, d.headers))
+}
+
+// addHeaders returns a copy of req carrying the headers it does not set
+// itself. A RoundTripper must not modify the request it is given.
+func addHeaders(req *http.Request, headers http.Header) *http.Request {
+	req = req.Clone(req.Context())
+	for name, values := range headers {
+		if req.Header.Get(name) == "" {
+			req.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return req
+}
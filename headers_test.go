@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// recordingTransport records the requests it forwards to the default
+// transport.
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// doerFunc adapts a function to openai.HTTPDoer.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithDefaultHeaders(t *testing.T) {
+	s, _ := newFakeServer(t)
+
+	recording := &recordingTransport{}
+	userClient := &http.Client{Transport: recording}
+	tests := []struct {
+		name       string
+		httpClient openai.HTTPDoer
+	}{
+		{name: "default client"},
+		{name: "user http client", httpClient: userClient},
+		{name: "user doer", httpClient: doerFunc(http.DefaultClient.Do)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := s.config()
+			cfg.HTTPClient = tt.httpClient
+			m := NewOpenAIModel("gpt-4o", cfg, WithDefaultHeaders(http.Header{
+				"X-Gateway-Key": {"secret"},
+				"Authorization": {"Bearer ignored"},
+			}))
+
+			for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hi"}}}},
+			}, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+			}
+
+			reqs := s.requestsTo("/chat/completions")
+			got := reqs[len(reqs)-1].header
+			if v := got.Get("X-Gateway-Key"); v != "secret" {
+				t.Errorf("X-Gateway-Key = %q, want %q", v, "secret")
+			}
+			if v := got.Get("Authorization"); v != "Bearer test" {
+				t.Errorf("Authorization = %q, want the client's own key", v)
+			}
+		})
+	}
+
+	if len(recording.requests) != 1 {
+		t.Errorf("user transport saw %d requests, want 1", len(recording.requests))
+	}
+	if _, ok := userClient.Transport.(*recordingTransport); !ok {
+		t.Error("user http.Client was modified")
+	}
+}
@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// StreamIdleError reports a stream that received nothing, not even a
+// heartbeat, for longer than OpenAIModel.StreamIdleTimeout.
+type StreamIdleError struct {
+	Timeout time.Duration
+}
+
+func (e *StreamIdleError) Error() string {
+	return fmt.Sprintf("stream received nothing for %v", e.Timeout)
+}
+
+// streamIdleDoer bounds the gaps between reads of every server-sent event
+// stream by the model's StreamIdleTimeout. Any bytes count as activity,
+// including the SSE comment lines some backends send as heartbeats, which
+// go-openai skips without surfacing.
+type streamIdleDoer struct {
+	doer  openai.HTTPDoer
+	model *OpenAIModel
+}
+
+func (d streamIdleDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	timeout := d.model.StreamIdleTimeout
+	if err != nil || timeout <= 0 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return resp, err
+	}
+	body := &idleBody{ReadCloser: resp.Body, timeout: timeout}
+	body.timer = time.AfterFunc(timeout, body.expire)
+	resp.Body = body
+	return resp, nil
+}
+
+// idleBody closes the underlying body once no bytes have arrived for timeout,
+// failing the pending and all later reads with a *StreamIdleError.
+type idleBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu      sync.Mutex
+	expired bool
+}
+
+func (b *idleBody) expire() {
+	b.mu.Lock()
+	b.expired = true
+	b.mu.Unlock()
+	b.ReadCloser.Close()
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.expired {
+		return 0, &StreamIdleError{Timeout: b.timeout}
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// newGappyStreamModel returns a model whose server streams "Hel" and "lo",
+// waiting gap between them while sending a heartbeat comment every
+// heartbeat, or none if heartbeat is zero.
+func newGappyStreamModel(t *testing.T, gap, heartbeat time.Duration) *OpenAIModel {
+	t.Helper()
+	s, m := newFakeServer(t)
+	s.chunks = []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hel"}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "lo"}, openai.FinishReasonStop),
+	}
+	s.chunkDelay = gap
+	s.heartbeat = heartbeat
+	m.StreamIdleTimeout = 100 * time.Millisecond
+	return m
+}
+
+func TestGenerateContent_StreamIdleTimeout(t *testing.T) {
+	req := weatherRequest()
+
+	t.Run("heartbeats keep the stream alive", func(t *testing.T) {
+		m := newGappyStreamModel(t, 300*time.Millisecond, 20*time.Millisecond)
+		var text string
+		for resp, err := range m.GenerateContent(context.Background(), req, true) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			if !resp.Partial {
+				text = resp.Content.Parts[0].Text
+			}
+		}
+		if text != "Hello" {
+			t.Errorf("final text = %q, want %q", text, "Hello")
+		}
+	})
+
+	t.Run("silence fails the stream", func(t *testing.T) {
+		m := newGappyStreamModel(t, 300*time.Millisecond, 0)
+		var idleErr *StreamIdleError
+		for _, err := range m.GenerateContent(context.Background(), req, true) {
+			if err != nil && !errors.As(err, &idleErr) {
+				t.Fatalf("GenerateContent() error = %v, want *StreamIdleError", err)
+			}
+		}
+		if idleErr == nil || idleErr.Timeout != 100*time.Millisecond {
+			t.Errorf("idle error = %v, want a 100ms *StreamIdleError", idleErr)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m := newGappyStreamModel(t, 200*time.Millisecond, 0)
+		m.StreamIdleTimeout = 0
+		var final *genai.Content
+		for resp, err := range m.GenerateContent(context.Background(), req, true) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+			final = resp.Content
+		}
+		if final == nil || final.Parts[0].Text != "Hello" {
+			t.Errorf("final content = %+v, want Hello", final)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+
+	// Register decoders for the formats transcodeImage can convert.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// supportedImageMIMETypes lists the image types OpenAI accepts as image_url
+// input.
+var supportedImageMIMETypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// transcodeImage re-encodes an image blob of a type OpenAI does not accept as
+// image/png. Blobs that are not images, or are already in a supported type,
+// are returned unchanged. An error is returned when the image cannot be
+// decoded.
+func transcodeImage(blob *genai.Blob) (*genai.Blob, error) {
+	mimeType := strings.ToLower(strings.TrimSpace(blob.MIMEType))
+	if !strings.HasPrefix(mimeType, "image/") || supportedImageMIMETypes[mimeType] {
+		return blob, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(blob.Data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image type %q cannot be transcoded: %w", blob.MIMEType, err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to transcode %q image to png: %w", blob.MIMEType, err)
+	}
+	return &genai.Blob{
+		DisplayName: blob.DisplayName,
+		Data:        buf.Bytes(),
+		MIMEType:    "image/png",
+	}, nil
+}
+
+// imageDetail returns the detail level for an image in part: the part's own
+// low or high MediaResolution if set, else ImageDetail, else auto. part may be
+// nil.
+func (o *OpenAIModel) imageDetail(part *genai.Part) openai.ImageURLDetail {
+	if part != nil && part.MediaResolution != nil {
+		switch part.MediaResolution.Level {
+		case genai.PartMediaResolutionLevelMediaResolutionLow:
+			return openai.ImageURLDetailLow
+		case genai.PartMediaResolutionLevelMediaResolutionHigh:
+			return openai.ImageURLDetailHigh
+		}
+	}
+	if o.ImageDetail != "" {
+		return o.ImageDetail
+	}
+	return openai.ImageURLDetailAuto
+}
+
+// inlineImagePart converts inline image data into an image_url part holding
+// a data URI, transcoding it first when TranscodeImages is set.
+func (o *OpenAIModel) inlineImagePart(blob *genai.Blob, detail openai.ImageURLDetail) (openai.ChatMessagePart, error) {
+	if o.TranscodeImages {
+		var err error
+		if blob, err = transcodeImage(blob); err != nil {
+			return openai.ChatMessagePart{}, err
+		}
+	}
+	base64Data := base64.StdEncoding.EncodeToString(blob.Data)
+	return openai.ChatMessagePart{
+		Type: openai.ChatMessagePartTypeImageURL,
+		ImageURL: &openai.ChatMessageImageURL{
+			URL:    fmt.Sprintf("data:%s;base64,%s", blob.MIMEType, base64Data),
+			Detail: detail,
+		},
+	}, nil
+}
+
+// remoteImageURL returns the URI of file data referencing an image over
+// http(s), which OpenAI can fetch directly. The MIME type decides whether it
+// is an image, or the URI's file extension when no MIME type is set.
+func remoteImageURL(fileData *genai.FileData) (string, bool) {
+	u, err := url.Parse(fileData.FileURI)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	mimeType := fileData.MIMEType
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(strings.ToLower(path.Ext(u.Path)))
+	}
+	if !strings.HasPrefix(strings.ToLower(mimeType), "image/") {
+		return "", false
+	}
+	return fileData.FileURI, true
+}
+
+// toolResponseImages converts the media parts of a function response into
+// image_url parts. Inline images are sent as data URIs, like those of
+// messages, and remote ones by URL; any other media fails with an
+// UnsupportedMediaError.
+func (o *OpenAIModel) toolResponseImages(parts []*genai.FunctionResponsePart) ([]openai.ChatMessagePart, error) {
+	var images []openai.ChatMessagePart
+	for _, part := range parts {
+		switch {
+		case part.InlineData != nil:
+			blob := part.InlineData
+			if !strings.HasPrefix(strings.ToLower(blob.MIMEType), "image/") {
+				return nil, &UnsupportedMediaError{MIMEType: blob.MIMEType}
+			}
+			imagePart, err := o.inlineImagePart(&genai.Blob{
+				DisplayName: blob.DisplayName,
+				Data:        blob.Data,
+				MIMEType:    blob.MIMEType,
+			}, o.imageDetail(nil))
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, imagePart)
+		case part.FileData != nil:
+			remote, ok := remoteImageURL(&genai.FileData{FileURI: part.FileData.FileURI, MIMEType: part.FileData.MIMEType})
+			if !ok {
+				return nil, &UnsupportedMediaError{MIMEType: part.FileData.MIMEType}
+			}
+			images = append(images, openai.ChatMessagePart{
+				Type:     openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{URL: remote, Detail: o.imageDetail(nil)},
+			})
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,258 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"golang.org/x/image/bmp"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionMessage_TranscodeImages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var bmpData bytes.Buffer
+	if err := bmp.Encode(&bmpData, src); err != nil {
+		t.Fatalf("bmp.Encode() error = %v", err)
+	}
+
+	m := &OpenAIModel{TranscodeImages: true}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{
+			{Text: "What is this?"},
+			{InlineData: &genai.Blob{MIMEType: "image/bmp", Data: bmpData.Bytes()}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+	if len(msgs) != 1 || len(msgs[0].MultiContent) != 2 {
+		t.Fatalf("got %+v, want one message with two parts", msgs)
+	}
+
+	url := msgs[0].MultiContent[1].ImageURL.URL
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(url, prefix) {
+		t.Fatalf("image URL = %.40q, want prefix %q", url, prefix)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(url, prefix))
+	if err != nil {
+		t.Fatalf("failed to decode image data: %v", err)
+	}
+	got, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if got.Bounds() != src.Bounds() {
+		t.Errorf("bounds = %v, want %v", got.Bounds(), src.Bounds())
+	}
+	if r, _, _, _ := got.At(1, 1).RGBA(); r != 0xffff {
+		t.Errorf("pixel (1,1) red = %#x, want 0xffff", r)
+	}
+}
+
+func TestTranscodeImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		blob     *genai.Blob
+		wantSame bool
+		wantErr  bool
+	}{
+		{name: "supported type", blob: &genai.Blob{MIMEType: "image/jpeg", Data: []byte("jpeg")}, wantSame: true},
+		{name: "non-image type", blob: &genai.Blob{MIMEType: "application/pdf", Data: []byte("pdf")}, wantSame: true},
+		{name: "undecodable type", blob: &genai.Blob{MIMEType: "image/heic", Data: []byte("heic")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transcodeImage(tt.blob)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("transcodeImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "image/heic") {
+				t.Errorf("error = %v, want it to name the MIME type", err)
+			}
+			if tt.wantSame && got != tt.blob {
+				t.Errorf("transcodeImage() = %+v, want blob unchanged", got)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_ImageDetail(t *testing.T) {
+	blob := &genai.Blob{MIMEType: "image/png", Data: []byte("png")}
+	highRes := &genai.PartMediaResolution{Level: genai.PartMediaResolutionLevelMediaResolutionHigh}
+
+	tests := []struct {
+		name   string
+		detail openai.ImageURLDetail
+		part   *genai.Part
+		want   openai.ImageURLDetail
+	}{
+		{name: "default", part: &genai.Part{InlineData: blob}, want: openai.ImageURLDetailAuto},
+		{name: "configured low", detail: openai.ImageURLDetailLow, part: &genai.Part{InlineData: blob}, want: openai.ImageURLDetailLow},
+		{name: "configured high", detail: openai.ImageURLDetailHigh, part: &genai.Part{InlineData: blob}, want: openai.ImageURLDetailHigh},
+		{
+			name:   "part override",
+			detail: openai.ImageURLDetailLow,
+			part:   &genai.Part{InlineData: blob, MediaResolution: highRes},
+			want:   openai.ImageURLDetailHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ImageDetail: tt.detail}
+			msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+				Role:  "user",
+				Parts: []*genai.Part{{Text: "Describe this"}, tt.part},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if got := msgs[0].MultiContent[1].ImageURL.Detail; got != tt.want {
+				t.Errorf("Detail = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_RemoteImage(t *testing.T) {
+	const imageURL = "https://example.com/images/cat.jpg?size=large"
+	m := &OpenAIModel{}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{
+			{Text: "What animal is this?"},
+			{FileData: &genai.FileData{MIMEType: "image/jpeg", FileURI: imageURL}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+
+	want := openai.ChatMessagePart{
+		Type:     openai.ChatMessagePartTypeImageURL,
+		ImageURL: &openai.ChatMessageImageURL{URL: imageURL, Detail: openai.ImageURLDetailAuto},
+	}
+	if len(msgs) != 1 || len(msgs[0].MultiContent) != 2 {
+		t.Fatalf("got %+v, want one message with two parts", msgs)
+	}
+	if diff := cmp.Diff(want, msgs[0].MultiContent[1]); diff != "" {
+		t.Errorf("image part mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoteImageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileData *genai.FileData
+		want     bool
+	}{
+		{name: "image MIME type", fileData: &genai.FileData{MIMEType: "image/png", FileURI: "https://example.com/a"}, want: true},
+		{name: "image extension", fileData: &genai.FileData{FileURI: "http://example.com/a.PNG"}, want: true},
+		{name: "non-image MIME type", fileData: &genai.FileData{MIMEType: "application/pdf", FileURI: "https://example.com/a.png"}},
+		{name: "cloud storage URI", fileData: &genai.FileData{MIMEType: "image/png", FileURI: "gs://bucket/a.png"}},
+		{name: "unknown extension", fileData: &genai.FileData{FileURI: "https://example.com/a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := remoteImageURL(tt.fileData); got != tt.want {
+				t.Errorf("remoteImageURL() ok = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_ToolResponseImage(t *testing.T) {
+	const chartURL = "https://example.com/charts/sales.png"
+	m := &OpenAIModel{}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       "call_1",
+			Name:     "render_chart",
+			Response: map[string]any{"result": "Rendered 2 charts"},
+			Parts: []*genai.FunctionResponsePart{
+				genai.NewFunctionResponsePartFromBytes([]byte("png"), "image/png"),
+				genai.NewFunctionResponsePartFromURI(chartURL, "image/png"),
+			},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+
+	want := []openai.ChatCompletionMessage{{
+		Role:       openai.ChatMessageRoleTool,
+		ToolCallID: "call_1",
+		MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "Rendered 2 charts"},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL:    "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("png")),
+				Detail: openai.ImageURLDetailAuto,
+			}},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL:    chartURL,
+				Detail: openai.ImageURLDetailAuto,
+			}},
+		},
+	}}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:    "call_2",
+			Name:  "export",
+			Parts: []*genai.FunctionResponsePart{genai.NewFunctionResponsePartFromBytes([]byte("%PDF"), "application/pdf")},
+		}}},
+	})
+	var mediaErr *UnsupportedMediaError
+	if !errors.As(err, &mediaErr) || mediaErr.MIMEType != "application/pdf" {
+		t.Errorf("toOpenAIChatCompletionMessage() error = %v, want UnsupportedMediaError for application/pdf", err)
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_ToolResponseImageTranscode(t *testing.T) {
+	var bmpData bytes.Buffer
+	if err := bmp.Encode(&bmpData, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("bmp.Encode() error = %v", err)
+	}
+
+	m := &OpenAIModel{TranscodeImages: true, ImageDetail: openai.ImageURLDetailLow}
+	msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       "call_1",
+			Name:     "screenshot",
+			Response: map[string]any{"result": "Captured"},
+			Parts:    []*genai.FunctionResponsePart{genai.NewFunctionResponsePartFromBytes(bmpData.Bytes(), "image/bmp")},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+	}
+	if len(msgs) != 1 || len(msgs[0].MultiContent) != 2 {
+		t.Fatalf("got %+v, want one tool message with two parts", msgs)
+	}
+	imageURL := msgs[0].MultiContent[1].ImageURL
+	if !strings.HasPrefix(imageURL.URL, "data:image/png;base64,") {
+		t.Errorf("image URL = %.40q, want a transcoded png", imageURL.URL)
+	}
+	if imageURL.Detail != openai.ImageURLDetailLow {
+		t.Errorf("Detail = %q, want %q", imageURL.Detail, openai.ImageURLDetailLow)
+	}
+}
@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// OpenAIImageGenerator creates images through the OpenAI images endpoint,
+// sharing client configuration with OpenAIModel.
+type OpenAIImageGenerator struct {
+	Client    *openai.Client
+	ModelName string
+}
+
+// ImageOptions tunes a single image generation. Zero values use the API
+// defaults.
+type ImageOptions struct {
+	// Size is the image size, such as openai.CreateImageSize1024x1024.
+	Size string
+
+	// Quality is the image quality, such as openai.CreateImageQualityHigh.
+	// The accepted values depend on the model.
+	Quality string
+
+	// N is the number of images to generate.
+	N int
+}
+
+func NewOpenAIImageGeneratorWithAPIKey(modelName string, apiKey string) *OpenAIImageGenerator {
+	cfg := openai.DefaultConfig(apiKey)
+	return NewOpenAIImageGenerator(modelName, cfg)
+}
+
+func NewOpenAIImageGenerator(modelName string, cfg openai.ClientConfig) *OpenAIImageGenerator {
+	client := openai.NewClientWithConfig(cfg)
+	return &OpenAIImageGenerator{
+		Client:    client,
+		ModelName: modelName,
+	}
+}
+
+// Generate returns the images created for prompt as blobs holding the decoded
+// image bytes, with their MIME type sniffed from the data.
+func (g *OpenAIImageGenerator) Generate(ctx context.Context, prompt string, opts ImageOptions) ([]genai.Blob, error) {
+	req := openai.ImageRequest{
+		Prompt:  prompt,
+		Model:   g.ModelName,
+		N:       opts.N,
+		Quality: opts.Quality,
+		Size:    opts.Size,
+	}
+	// GPT image models always return base64 data and reject response_format
+	if !strings.HasPrefix(g.ModelName, "gpt-image") {
+		req.ResponseFormat = openai.CreateImageResponseFormatB64JSON
+	}
+
+	resp, err := g.Client.CreateImage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]genai.Blob, 0, len(resp.Data))
+	for i, image := range resp.Data {
+		if image.B64JSON == "" {
+			return nil, fmt.Errorf("image %d has no base64 data", i)
+		}
+		data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+		}
+		blobs = append(blobs, genai.Blob{MIMEType: http.DetectContentType(data), Data: data})
+	}
+	return blobs, nil
+}
@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// newFakeImagesServer returns a generator whose server answers every image
+// request with N copies of pngData.
+func newFakeImagesServer(t *testing.T, modelName string, pngData []byte) (*fakeServer, *OpenAIImageGenerator) {
+	t.Helper()
+	s, _ := newFakeServer(t)
+	s.handle("/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ImageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		var resp openai.ImageResponse
+		for range max(req.N, 1) {
+			resp.Data = append(resp.Data, openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString(pngData)})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	return s, NewOpenAIImageGenerator(modelName, s.config())
+}
+
+func TestOpenAIImageGenerator_Generate(t *testing.T) {
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		model      string
+		wantFormat string
+	}{
+		{model: "dall-e-3", wantFormat: openai.CreateImageResponseFormatB64JSON},
+		{model: "gpt-image-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			s, generator := newFakeImagesServer(t, tt.model, pngData.Bytes())
+
+			got, err := generator.Generate(context.Background(), "a red fox", ImageOptions{
+				Size:    openai.CreateImageSize1024x1024,
+				Quality: openai.CreateImageQualityHigh,
+				N:       2,
+			})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			var req openai.ImageRequest
+			s.requestsTo("/images/generations")[0].decode(t, &req)
+			wantReq := openai.ImageRequest{
+				Prompt:         "a red fox",
+				Model:          tt.model,
+				N:              2,
+				Quality:        openai.CreateImageQualityHigh,
+				Size:           openai.CreateImageSize1024x1024,
+				ResponseFormat: tt.wantFormat,
+			}
+			if diff := cmp.Diff(wantReq, req); diff != "" {
+				t.Errorf("request mismatch (-want +got):\n%s", diff)
+			}
+			blob := genai.Blob{MIMEType: "image/png", Data: pngData.Bytes()}
+			if diff := cmp.Diff([]genai.Blob{blob, blob}, got); diff != "" {
+				t.Errorf("Generate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// WithLogger logs every GenerateContent call to logger: the request at debug
+// level with the model name and message count, the final response at info
+// level with its finish reason, token usage and duration, and errors at error
+// level. Message and response content is left out unless WithContentLogging
+// is also given.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *OpenAIModel) {
+		o.logger = logger
+	}
+}
+
+// WithContentLogging adds the request messages and the response text to the
+// records of WithLogger. They may hold personal or confidential data.
+func WithContentLogging() Option {
+	return func(o *OpenAIModel) {
+		o.logContent = true
+	}
+}
+
+// logRequest logs a request about to be sent.
+func (o *OpenAIModel) logRequest(ctx context.Context, req openai.ChatCompletionRequest) {
+	if o.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("model", req.Model),
+		slog.Bool("stream", req.Stream),
+		slog.Int("messages", len(req.Messages)),
+		slog.Int("tools", len(req.Tools)),
+	}
+	if o.logContent {
+		attrs = append(attrs, slog.Any("content", req.Messages))
+	}
+	o.logger.LogAttrs(ctx, slog.LevelDebug, "openai request", attrs...)
+}
+
+// logResponses wraps yield so that the final response or error of a call
+// started at start is logged before it is passed on.
+func (o *OpenAIModel) logResponses(ctx context.Context, stream bool, start time.Time, yield func(*model.LLMResponse, error) bool) func(*model.LLMResponse, error) bool {
+	if o.logger == nil {
+		return yield
+	}
+	return func(resp *model.LLMResponse, err error) bool {
+		attrs := []slog.Attr{
+			slog.String("model", o.ModelName),
+			slog.Bool("stream", stream),
+			slog.Duration("duration", time.Since(start)),
+		}
+		switch {
+		case err != nil:
+			attrs = append(attrs, slog.String("error", err.Error()))
+			o.logger.LogAttrs(ctx, slog.LevelError, "openai error", attrs...)
+		case !resp.Partial:
+			attrs = append(attrs, slog.String("finish_reason", string(resp.FinishReason)))
+			if usage := resp.UsageMetadata; usage != nil {
+				attrs = append(attrs,
+					slog.Int("prompt_tokens", int(usage.PromptTokenCount)),
+					slog.Int("completion_tokens", int(usage.CandidatesTokenCount)),
+					slog.Int("total_tokens", int(usage.TotalTokenCount)),
+				)
+			}
+			if o.logContent && resp.Content != nil {
+				attrs = append(attrs, slog.String("content", candidateText(resp.Content)))
+			}
+			o.logger.LogAttrs(ctx, slog.LevelInfo, "openai response", attrs...)
+		}
+		return yield(resp, err)
+	}
+}
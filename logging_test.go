@@ -0,0 +1,133 @@
+package openai
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// recordHandler is a slog.Handler capturing every record it handles.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+// attrs returns the attributes of the record with message msg.
+func (h *recordHandler) attrs(t *testing.T, msg string) map[string]slog.Value {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		attrs := map[string]slog.Value{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		return attrs
+	}
+	t.Fatalf("no %q record", msg)
+	return nil
+}
+
+func TestWithLogger(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		stream      bool
+		wantContent bool
+	}{
+		{name: "redacted", stream: false},
+		{name: "redacted stream", stream: true},
+		{name: "with content", opts: []Option{WithContentLogging()}, wantContent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordHandler{}
+			server, m := newFakeServer(t)
+			for _, opt := range append([]Option{WithLogger(slog.New(handler))}, tt.opts...) {
+				opt(m)
+			}
+			server.response = openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{
+					Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Bonjour"},
+					FinishReason: openai.FinishReasonStop,
+				}},
+				Usage: openai.Usage{PromptTokens: 9, CompletionTokens: 2, TotalTokens: 11},
+			}
+			server.chunks = []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Bonjour"}, openai.FinishReasonStop),
+				{Usage: &openai.Usage{PromptTokens: 9, CompletionTokens: 2, TotalTokens: 11}},
+			}
+
+			for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Say hello in French"}}}},
+			}, tt.stream) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+			}
+
+			req := handler.attrs(t, "openai request")
+			if got := req["model"].String(); got != "gpt-4o" {
+				t.Errorf("request model = %q, want %q", got, "gpt-4o")
+			}
+			if got := req["messages"].Int64(); got != 1 {
+				t.Errorf("request messages = %d, want 1", got)
+			}
+			if got := req["stream"].Bool(); got != tt.stream {
+				t.Errorf("request stream = %v, want %v", got, tt.stream)
+			}
+
+			resp := handler.attrs(t, "openai response")
+			if got := resp["total_tokens"].Int64(); got != 11 {
+				t.Errorf("response total_tokens = %d, want 11", got)
+			}
+			if got := resp["finish_reason"].String(); got != string(genai.FinishReasonStop) {
+				t.Errorf("response finish_reason = %q, want %q", got, genai.FinishReasonStop)
+			}
+
+			_, reqContent := req["content"]
+			_, respContent := resp["content"]
+			if reqContent != tt.wantContent || respContent != tt.wantContent {
+				t.Errorf("content logged in request %v and response %v, want %v", reqContent, respContent, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestWithLogger_Error(t *testing.T) {
+	handler := &recordHandler{}
+	m := NewOpenAIModel("gpt-4o", openai.DefaultConfig("test"), WithLogger(slog.New(handler)), WithValidateRequests())
+
+	for range m.GenerateContent(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hi"}}}},
+		Config:   &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](5)},
+	}, false) {
+	}
+
+	attrs := handler.attrs(t, "openai error")
+	if attrs["error"].String() == "" {
+		t.Error("error record has no error attribute")
+	}
+}
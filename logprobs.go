@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// convertLogprobs converts per-token logprobs into a genai logprobs result and
+// the average logprob of the chosen tokens. It returns nil and zero when there
+// are no tokens.
+func convertLogprobs(tokens []openai.LogProb) (*genai.LogprobsResult, float64) {
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+
+	result := &genai.LogprobsResult{
+		ChosenCandidates: make([]*genai.LogprobsResultCandidate, 0, len(tokens)),
+		TopCandidates:    make([]*genai.LogprobsResultTopCandidates, 0, len(tokens)),
+	}
+	var sum float64
+	for _, token := range tokens {
+		sum += token.LogProb
+		result.ChosenCandidates = append(result.ChosenCandidates, &genai.LogprobsResultCandidate{
+			Token:          token.Token,
+			LogProbability: float32(token.LogProb),
+		})
+
+		top := &genai.LogprobsResultTopCandidates{
+			Candidates: make([]*genai.LogprobsResultCandidate, 0, len(token.TopLogProbs)),
+		}
+		for _, alt := range token.TopLogProbs {
+			top.Candidates = append(top.Candidates, &genai.LogprobsResultCandidate{
+				Token:          alt.Token,
+				LogProbability: float32(alt.LogProb),
+			})
+		}
+		result.TopCandidates = append(result.TopCandidates, top)
+	}
+	return result, sum / float64(len(tokens))
+}
+
+// convertStreamTokenLogprob converts a streamed token logprob into the shape
+// used by non-streaming responses.
+func convertStreamTokenLogprob(token openai.ChatCompletionTokenLogprob) openai.LogProb {
+	logprob := openai.LogProb{
+		Token:   token.Token,
+		LogProb: token.Logprob,
+	}
+	for _, alt := range token.TopLogprobs {
+		logprob.TopLogProbs = append(logprob.TopLogProbs, openai.TopLogProbs{
+			Token:   alt.Token,
+			LogProb: alt.Logprob,
+		})
+	}
+	return logprob
+}
@@ -0,0 +1,126 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_Logprobs(t *testing.T) {
+	topN := int32(3)
+	tests := []struct {
+		name            string
+		cfg             *genai.GenerateContentConfig
+		wantLogProbs    bool
+		wantTopLogProbs int
+	}{
+		{name: "unset", cfg: &genai.GenerateContentConfig{}},
+		{name: "response logprobs", cfg: &genai.GenerateContentConfig{ResponseLogprobs: true}, wantLogProbs: true},
+		{name: "top logprobs", cfg: &genai.GenerateContentConfig{ResponseLogprobs: true, Logprobs: &topN}, wantLogProbs: true, wantTopLogProbs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o"}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+				Config:   tt.cfg,
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.LogProbs != tt.wantLogProbs {
+				t.Errorf("LogProbs = %v, want %v", got.LogProbs, tt.wantLogProbs)
+			}
+			if got.TopLogProbs != tt.wantTopLogProbs {
+				t.Errorf("TopLogProbs = %v, want %v", got.TopLogProbs, tt.wantTopLogProbs)
+			}
+		})
+	}
+}
+
+func TestConvertChatCompletionResponse_Logprobs(t *testing.T) {
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Hi!"},
+			FinishReason: openai.FinishReasonStop,
+			LogProbs: &openai.LogProbs{Content: []openai.LogProb{
+				{
+					Token:   "Hi",
+					LogProb: -0.5,
+					TopLogProbs: []openai.TopLogProbs{
+						{Token: "Hi", LogProb: -0.5},
+						{Token: "Hello", LogProb: -1.5},
+					},
+				},
+				{
+					Token:       "!",
+					LogProb:     -0.25,
+					TopLogProbs: []openai.TopLogProbs{{Token: "!", LogProb: -0.25}},
+				},
+			}},
+		}},
+	}
+
+	got, err := convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+
+	want := &genai.LogprobsResult{
+		ChosenCandidates: []*genai.LogprobsResultCandidate{
+			{Token: "Hi", LogProbability: -0.5},
+			{Token: "!", LogProbability: -0.25},
+		},
+		TopCandidates: []*genai.LogprobsResultTopCandidates{
+			{Candidates: []*genai.LogprobsResultCandidate{
+				{Token: "Hi", LogProbability: -0.5},
+				{Token: "Hello", LogProbability: -1.5},
+			}},
+			{Candidates: []*genai.LogprobsResultCandidate{
+				{Token: "!", LogProbability: -0.25},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, got.LogprobsResult); diff != "" {
+		t.Errorf("LogprobsResult mismatch (-want +got):\n%s", diff)
+	}
+	if got.AvgLogprobs != -0.375 {
+		t.Errorf("AvgLogprobs = %v, want -0.375", got.AvgLogprobs)
+	}
+}
+
+func TestReadStream_Logprobs(t *testing.T) {
+	chunk := func(content string, logprob float64) openai.ChatCompletionStreamResponse {
+		resp := deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: content}, "")
+		resp.Choices[0].Logprobs = &openai.ChatCompletionStreamChoiceLogprobs{
+			Content: []openai.ChatCompletionTokenLogprob{{Token: content, Logprob: logprob}},
+		}
+		return resp
+	}
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		chunk("Hi", -1),
+		chunk("!", -0.5),
+	}}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+	final := resps[len(resps)-1]
+
+	want := []*genai.LogprobsResultCandidate{
+		{Token: "Hi", LogProbability: -1},
+		{Token: "!", LogProbability: -0.5},
+	}
+	if final.LogprobsResult == nil {
+		t.Fatal("LogprobsResult = nil")
+	}
+	if diff := cmp.Diff(want, final.LogprobsResult.ChosenCandidates); diff != "" {
+		t.Errorf("ChosenCandidates mismatch (-want +got):\n%s", diff)
+	}
+	if final.AvgLogprobs != -0.75 {
+		t.Errorf("AvgLogprobs = %v, want -0.75", final.AvgLogprobs)
+	}
+}
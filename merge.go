@@ -0,0 +1,62 @@
+package openai
+
+import "github.com/sashabaranov/go-openai"
+
+// mergeConsecutiveMessages coalesces adjacent messages of the same role and
+// name into one. Texts are joined by sep, and when either message has several
+// parts the merged message carries the parts of both. Assistant tool calls are
+// combined, while tool messages are kept apart as each answers its own call.
+func mergeConsecutiveMessages(msgs []openai.ChatCompletionMessage, sep string) []openai.ChatCompletionMessage {
+	merged := make([]openai.ChatCompletionMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		if n := len(merged); n > 0 && canMergeMessages(merged[n-1], msg) {
+			merged[n-1] = mergeMessages(merged[n-1], msg, sep)
+			continue
+		}
+		merged = append(merged, msg)
+	}
+	return merged
+}
+
+func canMergeMessages(prev, msg openai.ChatCompletionMessage) bool {
+	return prev.Role == msg.Role && prev.Name == msg.Name && msg.Role != openai.ChatMessageRoleTool
+}
+
+func mergeMessages(first, second openai.ChatCompletionMessage, sep string) openai.ChatCompletionMessage {
+	merged := first
+	merged.ToolCalls = append(append([]openai.ToolCall(nil), first.ToolCalls...), second.ToolCalls...)
+	if len(merged.ToolCalls) == 0 {
+		merged.ToolCalls = nil
+	}
+	merged.ReasoningContent = joinNonEmpty(first.ReasoningContent, second.ReasoningContent, sep)
+
+	if len(first.MultiContent) == 0 && len(second.MultiContent) == 0 {
+		merged.Content = joinNonEmpty(first.Content, second.Content, sep)
+		return merged
+	}
+	merged.Content = ""
+	merged.MultiContent = append(messageParts(first), messageParts(second)...)
+	return merged
+}
+
+// messageParts returns the content of msg as parts.
+func messageParts(msg openai.ChatCompletionMessage) []openai.ChatMessagePart {
+	if len(msg.MultiContent) > 0 {
+		return msg.MultiContent
+	}
+	if msg.Content == "" {
+		return nil
+	}
+	return []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: msg.Content}}
+}
+
+func joinNonEmpty(a, b, sep string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + sep + b
+	}
+}
@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_MergeConsecutive(t *testing.T) {
+	m := &OpenAIModel{ModelName: "gpt-4o", MergeConsecutive: true}
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}},
+			{Role: "user", Parts: []*genai.Part{{Text: "Are you there?"}}},
+			{Role: "model", Parts: []*genai.Part{{Text: "Yes."}}},
+			{Role: "user", Parts: []*genai.Part{{Text: "Look at this"}}},
+			{Role: "user", Parts: []*genai.Part{
+				{Text: "and this"},
+				{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("png")}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+
+	want := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "Hello\nAre you there?"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "Yes."},
+		{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: "Look at this"},
+			{Type: openai.ChatMessagePartTypeText, Text: "and this"},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+				URL:    "data:image/png;base64,cG5n",
+				Detail: openai.ImageURLDetailAuto,
+			}},
+		}},
+	}
+	if diff := cmp.Diff(want, got.Messages); diff != "" {
+		t.Errorf("Messages mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeConsecutiveMessages_ToolMessages(t *testing.T) {
+	msgs := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}}},
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_2"}}},
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "a"},
+		{Role: openai.ChatMessageRoleTool, ToolCallID: "call_2", Content: "b"},
+	}
+
+	want := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}, {ID: "call_2"}}},
+		msgs[2],
+		msgs[3],
+	}
+	if diff := cmp.Diff(want, mergeConsecutiveMessages(msgs, "\n")); diff != "" {
+		t.Errorf("mergeConsecutiveMessages() mismatch (-want +got):\n%s", diff)
+	}
+}
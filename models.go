@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// modelsPageKey is the context key under which ListModels passes the
+// *modelsPage of each request to modelsPageDoer.
+type modelsPageKey struct{}
+
+// modelsPage is the pagination state of a models list request: the cursor to
+// send and what the response reported. go-openai neither sends nor decodes
+// these fields.
+type modelsPage struct {
+	after   string
+	hasMore bool
+	lastID  string
+}
+
+// modelsPageDoer adds the cursor of the request context's modelsPage to
+// models list requests and records the pagination fields of the response.
+type modelsPageDoer struct {
+	doer openai.HTTPDoer
+}
+
+func (d modelsPageDoer) Do(req *http.Request) (*http.Response, error) {
+	page, ok := req.Context().Value(modelsPageKey{}).(*modelsPage)
+	if !ok || req.Method != http.MethodGet || !strings.HasSuffix(req.URL.Path, "/models") {
+		return d.doer.Do(req)
+	}
+	if page.after != "" {
+		// OpenAI-style lists page with after, Anthropic's with after_id
+		query := req.URL.Query()
+		query.Set("after", page.after)
+		query.Set("after_id", page.after)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		return resp, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var fields struct {
+		HasMore bool   `json:"has_more"`
+		LastID  string `json:"last_id"`
+	}
+	if json.Unmarshal(data, &fields) == nil {
+		page.hasMore, page.lastID = fields.HasMore, fields.LastID
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// ListModels returns the IDs of the models the endpoint serves. Backends that
+// page the list, reporting has_more and last_id, are followed to the last
+// page.
+func (o *OpenAIModel) ListModels(ctx context.Context) ([]string, error) {
+	page := &modelsPage{}
+	ctx = context.WithValue(ctx, modelsPageKey{}, page)
+	var ids []string
+	for {
+		list, err := o.Client.ListModels(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range list.Models {
+			ids = append(ids, model.ID)
+		}
+
+		next := page.lastID
+		if next == "" && len(list.Models) > 0 {
+			next = list.Models[len(list.Models)-1].ID
+		}
+		if !page.hasMore || next == "" || next == page.after {
+			return ids, nil
+		}
+		*page = modelsPage{after: next}
+	}
+}
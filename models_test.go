@@ -0,0 +1,63 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newModelsServerModel returns a model whose server lists ids in pages of
+// pageSize, or all at once without pagination fields if pageSize is zero.
+func newModelsServerModel(t *testing.T, ids []string, pageSize int) *OpenAIModel {
+	t.Helper()
+	s, m := newFakeServer(t)
+	s.handle("/models", func(w http.ResponseWriter, r *http.Request) {
+		page := ids
+		resp := map[string]any{"object": "list"}
+		if pageSize > 0 {
+			start := 0
+			for i, id := range ids {
+				if id == r.URL.Query().Get("after") {
+					start = i + 1
+				}
+			}
+			end := min(start+pageSize, len(ids))
+			page = ids[start:end]
+			resp["has_more"] = end < len(ids)
+			resp["last_id"] = ids[end-1]
+		}
+		var data []map[string]any
+		for _, id := range page {
+			data = append(data, map[string]any{"id": id, "object": "model"})
+		}
+		resp["data"] = data
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+	return m
+}
+
+func TestListModels(t *testing.T) {
+	ids := []string{"gpt-4o", "gpt-4o-mini", "o3", "o4-mini", "text-embedding-3-small"}
+	for _, tt := range []struct {
+		name     string
+		pageSize int
+	}{
+		{name: "single list"},
+		{name: "paginated", pageSize: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModelsServerModel(t, ids, tt.pageSize)
+			got, err := m.ListModels(context.Background())
+			if err != nil {
+				t.Fatalf("ListModels() error = %v", err)
+			}
+			if diff := cmp.Diff(ids, got); diff != "" {
+				t.Errorf("ListModels() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Moderate checks input against OpenAI's usage policies with the model's
+// client and credentials. It reports whether input was flagged and, keyed by
+// category name such as "hate" or "self-harm/intent", which categories were.
+func (o *OpenAIModel) Moderate(ctx context.Context, input string) (flagged bool, categories map[string]bool, err error) {
+	resp, err := o.Client.Moderations(ctx, openai.ModerationRequest{
+		Input: input,
+		Model: o.ModerationModel,
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	if len(resp.Results) == 0 {
+		return false, nil, errors.New("no results in moderation response")
+	}
+
+	result := resp.Results[0]
+	data, err := json.Marshal(result.Categories)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to marshal moderation categories: %w", err)
+	}
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return false, nil, fmt.Errorf("failed to decode moderation categories: %w", err)
+	}
+	return result.Flagged, categories, nil
+}
@@ -0,0 +1,62 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestModerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		result   openai.Result
+		wantFlag bool
+	}{
+		{
+			name:     "flagged",
+			model:    openai.ModerationOmniLatest,
+			result:   openai.Result{Flagged: true, Categories: openai.ResultCategories{Harassment: true, Violence: true}},
+			wantFlag: true,
+		},
+		{
+			name:   "unflagged",
+			result: openai.Result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			s.handle("/moderations", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(openai.ModerationResponse{Results: []openai.Result{tt.result}})
+			})
+			m.ModerationModel = tt.model
+
+			flagged, categories, err := m.Moderate(context.Background(), "some input")
+			if err != nil {
+				t.Fatalf("Moderate() error = %v", err)
+			}
+			var got openai.ModerationRequest
+			s.requestsTo("/moderations")[0].decode(t, &got)
+			if got.Input != "some input" || got.Model != tt.model {
+				t.Errorf("request = %+v, want input %q and model %q", got, "some input", tt.model)
+			}
+			if flagged != tt.wantFlag {
+				t.Errorf("flagged = %v, want %v", flagged, tt.wantFlag)
+			}
+			if categories["harassment"] != tt.result.Categories.Harassment ||
+				categories["violence"] != tt.result.Categories.Violence ||
+				categories["self-harm"] {
+				t.Errorf("categories = %v, want them to match %+v", categories, tt.result.Categories)
+			}
+			if _, ok := categories["hate/threatening"]; !ok {
+				t.Errorf("categories = %v, want every category present", categories)
+			}
+		})
+	}
+}
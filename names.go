@@ -0,0 +1,55 @@
+package openai
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// maxMessageNameLength is the longest message name OpenAI accepts.
+const maxMessageNameLength = 64
+
+// messageName returns the sanitized name of the participant that authored
+// content, or "" when MessageName is unset or yields nothing.
+func (o *OpenAIModel) messageName(content *genai.Content) string {
+	if o.MessageName == nil {
+		return ""
+	}
+	return sanitizeMessageName(o.MessageName(content))
+}
+
+// sanitizeMessageName makes name match OpenAI's ^[a-zA-Z0-9_-]{1,64}$ by
+// replacing every other character with an underscore and truncating it.
+func sanitizeMessageName(name string) string {
+	name = strings.TrimSpace(name)
+	var b strings.Builder
+	for _, r := range name {
+		if b.Len() == maxMessageNameLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// setMessageName names every message converted from content except tool
+// results, which are matched to their call by ID, and legacy function
+// results, whose name is the function that produced them.
+func setMessageName(msgs []openai.ChatCompletionMessage, name string) {
+	if name == "" {
+		return
+	}
+	for i := range msgs {
+		switch msgs[i].Role {
+		case openai.ChatMessageRoleTool, openai.ChatMessageRoleFunction:
+		default:
+			msgs[i].Name = name
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestSanitizeMessageName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "valid", in: "research_agent-2", want: "research_agent-2"},
+		{name: "spaces", in: "Research Agent", want: "Research_Agent"},
+		{name: "surrounding spaces", in: "  planner ", want: "planner"},
+		{name: "invalid characters", in: "agent.v1/éval!", want: "agent_v1__val_"},
+		{name: "empty", in: "", want: ""},
+		{name: "truncated", in: strings.Repeat("a", 70), want: strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeMessageName(tt.in); got != tt.want {
+				t.Errorf("sanitizeMessageName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_MessageName(t *testing.T) {
+	authors := map[*genai.Content]string{}
+	question := &genai.Content{Role: "user", Parts: []*genai.Part{{Text: "Plan a trip"}}}
+	call := &genai.Content{Role: "model", Parts: []*genai.Part{{
+		FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "search", Args: map[string]any{"q": "Paris"}},
+	}}}
+	result := &genai.Content{Role: "user", Parts: []*genai.Part{{
+		FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "search", Response: map[string]any{"ok": true}},
+	}}}
+	answer := &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "Go in spring."}}}
+	authors[call] = "Travel Planner"
+	authors[answer] = "travel.writer"
+
+	m := &OpenAIModel{
+		ModelName:   "gpt-4o",
+		MessageName: func(content *genai.Content) string { return authors[content] },
+	}
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{question, call, result, answer},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+
+	want := []struct{ role, name string }{
+		{openai.ChatMessageRoleUser, ""},
+		{openai.ChatMessageRoleAssistant, "Travel_Planner"},
+		{openai.ChatMessageRoleTool, ""},
+		{openai.ChatMessageRoleAssistant, "travel_writer"},
+	}
+	if len(got.Messages) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got.Messages), len(want))
+	}
+	for i, w := range want {
+		if got.Messages[i].Role != w.role || got.Messages[i].Name != w.name {
+			t.Errorf("message %d = (%s, %q), want (%s, %q)", i, got.Messages[i].Role, got.Messages[i].Name, w.role, w.name)
+		}
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_MessageNameLegacyFunction(t *testing.T) {
+	result := &genai.Content{Role: "user", Parts: []*genai.Part{{
+		FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "search", Response: map[string]any{"ok": true}},
+	}}}
+
+	m := &OpenAIModel{
+		ModelName:   "gpt-4o",
+		Profile:     ProfileLegacy,
+		MessageName: func(*genai.Content) string { return "planner" },
+	}
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{result},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+
+	if len(got.Messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(got.Messages))
+	}
+	if msg := got.Messages[0]; msg.Role != openai.ChatMessageRoleFunction || msg.Name != "search" {
+		t.Errorf("message = (%s, %q), want (%s, %q)", msg.Role, msg.Name, openai.ChatMessageRoleFunction, "search")
+	}
+}
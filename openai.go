@@ -1,13 +1,21 @@
 package openai
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/sashabaranov/go-openai"
 	"google.golang.org/adk/model"
@@ -19,24 +27,404 @@ var _ model.LLM = &OpenAIModel{}
 var (
 	ErrNoChoicesInResponse   = errors.New("no choices in OpenAI response")
 	ErrUnknownPartInResponse = errors.New("unknown part type in genai content")
+	ErrEmptyModelName        = errors.New("model name is empty")
+	ErrMissingAPIKey         = errors.New("API key is empty and no base URL is set")
 )
 
+// CandidatesMetadataKey is the LLMResponse.CustomMetadata key under which every
+// candidate is stored as []*genai.Candidate when more than one was requested
+// through GenerateContentConfig.CandidateCount. The response Content holds the
+// first candidate. With native n, OpenAI reports usage aggregated over all
+// candidates rather than per candidate, and UsageMetadata carries that total.
+const CandidatesMetadataKey = "candidates"
+
+// ServiceTierMetadataKey is the LLMResponse.CustomMetadata key under which the
+// service tier that processed a non-streaming request is stored as a string.
+const ServiceTierMetadataKey = "service_tier"
+
+// FinishReasonMetadataKey is the LLMResponse.CustomMetadata key under which
+// the backend's finish reason, "tool_calls" or "function_call", is stored as
+// a string when a turn ended to wait on tool results. FinishReason reports
+// such turns as FinishReasonStop, like a completed answer.
+const FinishReasonMetadataKey = "finish_reason"
+
+// Metadata keys under which LLMResponse.CustomMetadata reports, as strings,
+// the concrete model that served a request, such as gpt-4o-2024-08-06 for
+// the gpt-4o alias, and the fingerprint of the backend configuration.
+const (
+	ModelVersionMetadataKey      = "model_version"
+	SystemFingerprintMetadataKey = "system_fingerprint"
+)
+
+// Profile selects the wire conventions of the backend an OpenAIModel talks to.
+type Profile string
+
+const (
+	// ProfileOpenAI targets the current OpenAI chat completions API and the
+	// many servers that mirror it. It is the default.
+	ProfileOpenAI Profile = ""
+	// ProfileLegacy targets endpoints that predate tool calling, which expect
+	// function results under role "function" with the function name rather
+	// than role "tool" with a tool_call_id.
+	ProfileLegacy Profile = "legacy"
+	// ProfileOpenRouter targets OpenRouter and similar gateways. It maps
+	// GenerateContentConfig.RoutingConfig onto the gateway's provider routing
+	// parameters. Those travel outside the typed request, so they are only sent
+	// by models created with NewOpenAIModel.
+	ProfileOpenRouter Profile = "openrouter"
+)
+
+// OpenAIModel implements model.LLM on OpenAI-compatible chat completions.
+//
+// An OpenAIModel is safe for concurrent use by multiple goroutines once
+// configured: calls only read its fields and keep all per-call state local.
+// Set the fields before the first call and do not change them afterwards;
+// maps and configs assigned to it, such as ExtraBody and DefaultConfig, must
+// not be modified while calls may be in flight either.
 type OpenAIModel struct {
+	// Client is the go-openai client chat completions are sent through. It
+	// can also be used directly for endpoints this package does not wrap,
+	// such as files, moderations or fine-tuning.
 	Client    *openai.Client
 	ModelName string
+
+	// Profile selects the wire conventions of the backend.
+	Profile Profile
+
+	// User is the end-user identifier sent with every request for abuse
+	// monitoring. ContextWithUser overrides it per request.
+	User string
+
+	// DropEmptyAssistantMessages removes assistant messages that carry neither
+	// content nor tool calls from the outgoing request. Such messages appear when
+	// replaying history with empty model turns and are rejected by strict providers.
+	DropEmptyAssistantMessages bool
+
+	// StreamToolCallDeltas yields a partial response each time a streamed tool
+	// call grows, in addition to the consolidated final response. Partial
+	// function calls carry the name as soon as it is known and the arguments
+	// parsed so far, with WillContinue set. Leave this off when the consumer
+	// executes function calls from every response, as the ADK flows do.
+	StreamToolCallDeltas bool
+
+	// StreamSentences buffers streamed text and yields partial responses only
+	// at sentence boundaries instead of for every token delta, which suits
+	// sentence-by-sentence consumers such as TTS. The final response still
+	// carries the full text.
+	StreamSentences bool
+
+	// SentenceDelimiters lists the characters that end a sentence when
+	// StreamSentences is set. Empty uses ".!?", newline and their CJK forms.
+	SentenceDelimiters string
+
+	// StreamUsageEstimates adds a running estimate of the completion tokens
+	// streamed so far to every partial response, under
+	// EstimatedCompletionTokensMetadataKey, for progress displays. It is
+	// counted locally with the model's tokenizer; the final response still
+	// reports the backend's usage.
+	StreamUsageEstimates bool
+
+	// StreamUsage requests token usage for streaming calls through
+	// stream_options.include_usage, reported on the final response. It is off
+	// by default because some OpenAI-compatible servers, such as older vLLM
+	// releases, reject the field; enable it for the OpenAI API. A server that
+	// accepts the field but never sends usage leaves UsageMetadata nil.
+	StreamUsage bool
+
+	// LenientStreamEnd accommodates local servers such as Ollama, vLLM and
+	// llama.cpp that may end a stream without a finish_reason. A stream that
+	// produced content and then ended cleanly reports FinishReasonStop instead
+	// of leaving it unset. Usage that never arrives is left nil either way.
+	LenientStreamEnd bool
+
+	// FanOutCandidates emulates GenerateContentConfig.CandidateCount for
+	// backends without n support by sending one request per candidate in
+	// parallel. Usage from every request is summed into the response. Only
+	// non-streaming calls fan out; streaming calls always send n.
+	FanOutCandidates bool
+
+	// MaxTokens limits the completion length of requests whose
+	// MaxOutputTokens is zero. GenerateContentConfig.MaxOutputTokens cannot
+	// distinguish zero from unset, so a zero there always means no limit is
+	// sent and the server's own maximum applies; MaxTokens gives such requests
+	// an explicit budget, which reasoning models often need to be large.
+	MaxTokens int
+
+	// DefaultConfig holds generation settings applied to every request. Fields
+	// set on the request's own config take precedence over these defaults.
+	DefaultConfig *genai.GenerateContentConfig
+
+	// ReplayReasoning sends the thought parts of earlier assistant turns back
+	// as the reasoning_content field, which DeepSeek-style endpoints use to
+	// continue multi-turn reasoning. Without it, thought parts are stripped
+	// from the request, as the OpenAI API has no field for them.
+	ReplayReasoning bool
+
+	// GroupCandidates groups candidates with identical text when several
+	// are requested, storing the groups under CandidateGroupsMetadataKey so
+	// callers can majority-vote over sampled answers.
+	GroupCandidates bool
+
+	// PreserveToolArguments keeps the exact argument string of each tool call
+	// the model makes, keyed by call ID, and sends it back verbatim when the
+	// call is replayed instead of re-marshaling Args, which reorders keys.
+	// Backends that check argument echoes need this. If Args was changed
+	// since, it is marshaled afresh. The strings are kept for the lifetime of
+	// the model.
+	PreserveToolArguments bool
+
+	// StructuredContent always sends message content as an array of parts,
+	// even for a single text part that is otherwise sent as a plain string.
+	// Some gateways treat the two forms differently.
+	StructuredContent bool
+
+	// StructuredToolContent sends the content of tool messages as an array
+	// holding a single text part instead of a plain string, for backends that
+	// require the array form. The legacy profile's function messages keep the
+	// string form.
+	StructuredToolContent bool
+
+	// PromoteDataURIImages turns data:image URIs embedded in text parts into
+	// image_url parts, for producers that inline images in text rather than
+	// using InlineData.
+	PromoteDataURIImages bool
+
+	// LenientTools skips nil function declarations and genai built-in tools
+	// without a chat completions counterpart, such as Google Search, instead
+	// of failing the request with an error or an *UnsupportedToolsError.
+	LenientTools bool
+
+	// StrictTools declares every function tool strict, so the model's
+	// arguments always match the parameters schema. Each schema is rewritten to
+	// forbid additional properties and require every property, making optional
+	// ones nullable; a schema using features strict mode rejects fails the
+	// request.
+	StrictTools bool
+
+	// ResponseSchemaFormat selects how GenerateContentConfig.ResponseSchema
+	// is sent: as strict Structured Outputs by default, as a non-strict
+	// json_schema, or as plain JSON mode.
+	ResponseSchemaFormat ResponseSchemaFormat
+
+	// NullableStyle selects how nullable fields of tool parameter schemas are
+	// expressed.
+	NullableStyle NullableStyle
+
+	// MergeConsecutive coalesces adjacent messages of the same role into
+	// one, joining their texts with TextSeparator, for backends that reject
+	// or handle such sequences poorly. Tool messages are never merged.
+	MergeConsecutive bool
+
+	// TextSeparator joins the text parts of content that is sent as a single
+	// string, such as a system instruction. Nil uses a newline; point it at
+	// an empty string to concatenate the parts as they are.
+	TextSeparator *string
+
+	// MultimodalSystemInstruction sends images and other non-text parts of the
+	// system instruction as parts of the system message. OpenAI itself only
+	// accepts text there, so by default just the instruction's text is sent.
+	MultimodalSystemInstruction bool
+
+	// RawFunctionResponses sends every function response as the JSON of its
+	// whole Response map. By default a response holding only a "result" key,
+	// which ADK uses for tools that return a string, number or list, is sent
+	// as that value alone, with strings as plain text.
+	RawFunctionResponses bool
+
+	// ImageDetail sets the detail level of image inputs: auto, low for fewer
+	// tokens, or high for fine-grained analysis. Empty means auto. A part's
+	// MediaResolution level of low or high overrides it for that image.
+	ImageDetail openai.ImageURLDetail
+
+	// TranscodeImages re-encodes InlineData images in types OpenAI does not
+	// accept (e.g. image/bmp, image/tiff) as image/png before sending. Images
+	// that cannot be decoded, such as image/heic, fail the request with an
+	// error instead.
+	TranscodeImages bool
+
+	// ExtraBody holds top-level fields merged into the JSON body of every chat
+	// completion request, for parameters this package does not map. On a
+	// collision the value mapped from the request wins. ContextWithExtraBody
+	// adds fields per request. Only models created with NewOpenAIModel send
+	// them.
+	ExtraBody map[string]any
+
+	// ForwardTopK sends the config's TopK as a top_k body field. The OpenAI
+	// API rejects it, but backends such as Ollama, vLLM and OpenRouter accept
+	// it. Without it, TopK is dropped.
+	ForwardTopK bool
+
+	// LogitBias adjusts the likelihood of tokens, keyed by token ID as a
+	// string, with values in [-100, 100]. -100 bans a token and 100 forces
+	// it.
+	LogitBias map[string]int
+
+	// ServiceTier selects the processing tier trading cost against latency:
+	// auto, default, flex or priority. Empty leaves the choice to the project
+	// settings.
+	ServiceTier openai.ServiceTier
+
+	// Store asks OpenAI to keep the completions for use in distillation and
+	// evals in its dashboard.
+	Store bool
+
+	// Metadata holds key-value pairs attached to every request, for filtering
+	// stored completions. OpenAI allows at most 16 pairs, with keys of up to 64
+	// characters and values of up to 512.
+	Metadata map[string]string
+
+	// RefusalAsError fails a call the model refused with a *RefusalError
+	// instead of returning the refusal as response text.
+	RefusalAsError bool
+
+	// FailOnTruncation fails calls whose response hit the output token limit
+	// with a *TruncatedError carrying the truncated response, instead of
+	// returning it as a normal completion.
+	FailOnTruncation bool
+
+	// AbortOnContentFilter stops a stream as soon as a choice finishes with
+	// the content_filter reason, failing with a *SafetyError that carries the
+	// content streamed so far, instead of aggregating a final response.
+	AbortOnContentFilter bool
+
+	// CaptureRawResponses keeps the exact bytes of every response body and
+	// reports them on the final response under RawResponsesMetadataKey, for
+	// callers that must persist what the API returned. It only works with
+	// models created by NewOpenAIModel.
+	CaptureRawResponses bool
+
+	// Prices overrides DefaultPrices for EstimateCost, keyed by model name
+	// prefix.
+	Prices map[string]ModelPrice
+
+	// FinishReasons maps backend finish reasons onto genai ones, overriding
+	// the built-in mapping for the reasons it lists. Keys are matched
+	// exactly.
+	FinishReasons map[string]genai.FinishReason
+
+	// OnRateLimit, if set, receives the x-ratelimit-* headers of every
+	// response the model's client gets, including 429 errors, so callers can
+	// throttle themselves. Responses without such headers are not reported.
+	// It may be called concurrently.
+	OnRateLimit func(limits openai.RateLimitHeaders)
+
+	// ModerationModel selects the model Moderate uses, such as
+	// omni-moderation-latest. Empty uses the API default.
+	ModerationModel string
+
+	// ValidateRequests checks every built request against OpenAI's parameter
+	// ranges, tool name uniqueness, tool message sequencing (every tool
+	// message answers a preceding tool call by ID and every tool call is
+	// answered) and image input to models known to lack vision before it is
+	// sent, failing with all violations at once instead of a round-trip 400.
+	ValidateRequests bool
+
+	// MessageName, if set, returns the name of the participant that authored
+	// content, such as the ADK agent that produced a model turn. The name is
+	// sent on the content's messages so the model can tell multiple agents
+	// apart; characters OpenAI does not allow in names become underscores.
+	MessageName func(content *genai.Content) string
+
+	// StreamReconnects is how many times a streaming call reissues its
+	// request after a retryable failure, such as a rate limit, a server error
+	// or a dropped connection, that happens before anything was yielded. Once
+	// output has been delivered, failures are returned instead, so no output
+	// is replayed.
+	StreamReconnects int
+
+	// RequestTimeout bounds every call without the caller having to set a
+	// deadline on its context; a deadline already on the context still
+	// applies. For streaming calls it covers the whole stream unless
+	// RequestTimeoutConnectOnly is set. Zero means no timeout.
+	RequestTimeout time.Duration
+
+	// RequestTimeoutConnectOnly limits RequestTimeout, for streaming calls, to
+	// the wait for the stream to start, so long generations are not cut off
+	// once tokens are flowing.
+	RequestTimeoutConnectOnly bool
+
+	// StreamIdleTimeout fails a stream with a *StreamIdleError once it has
+	// received nothing for this long. Heartbeats such as SSE comment lines
+	// count as activity, so long reasoning turns that keep the connection
+	// alive are not cut off. Zero waits indefinitely. It only works with
+	// models created by NewOpenAIModel.
+	StreamIdleTimeout time.Duration
+
+	// OnRequest, if set, is called with every chat completion request right
+	// before it is sent.
+	OnRequest func(ctx context.Context, req *openai.ChatCompletionRequest)
+
+	// OnFirstToken, if set, is called once per streaming call with the time
+	// from sending the request to receiving the first chunk that carries
+	// content, reasoning or a tool call.
+	OnFirstToken func(d time.Duration)
+
+	// OnResponse, if set, is called with the final response of every call, or
+	// with the error that ended it. Partial streaming responses are skipped.
+	OnResponse func(ctx context.Context, resp *model.LLMResponse, err error)
+
+	// baseURL and rawBaseURL hold the WithBaseURL and
+	// WithoutBaseURLNormalization options until the client is created.
+	baseURL    string
+	rawBaseURL bool
+
+	// logger and logContent hold the WithLogger and WithContentLogging
+	// options.
+	logger     *slog.Logger
+	logContent bool
+
+	// defaultHeaders holds the WithDefaultHeaders option until the client is
+	// created.
+	defaultHeaders http.Header
+
+	// toolArguments maps the ID of every tool call seen with
+	// PreserveToolArguments to the raw argument string the model emitted.
+	toolArguments sync.Map
+}
+
+func NewOpenAIModelWithAPIKey(modelName string, apiKey string, opts ...Option) *OpenAIModel {
+	cfg := openai.DefaultConfig(apiKey)
+	return NewOpenAIModel(modelName, cfg, opts...)
 }
 
-func NewOpenAIModelWithAPIKey(modelName string, apiKey string) *OpenAIModel {
+// NewCheckedOpenAIModel is like NewOpenAIModelWithAPIKey but reports a
+// misconfiguration up front instead of at the first request. The model name
+// must be set, and so must apiKey unless baseURL points at a server that needs
+// no key, such as a local Ollama. An empty baseURL uses the OpenAI API.
+func NewCheckedOpenAIModel(modelName, apiKey, baseURL string, opts ...Option) (*OpenAIModel, error) {
+	if strings.TrimSpace(modelName) == "" {
+		return nil, ErrEmptyModelName
+	}
+	if apiKey == "" && baseURL == "" {
+		return nil, ErrMissingAPIKey
+	}
 	cfg := openai.DefaultConfig(apiKey)
-	return NewOpenAIModel(modelName, cfg)
+	if baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	return NewOpenAIModel(modelName, cfg, opts...), nil
 }
 
-func NewOpenAIModel(modelName string, cfg openai.ClientConfig) *OpenAIModel {
-	client := openai.NewClientWithConfig(cfg)
-	return &OpenAIModel{
-		Client:    client,
-		ModelName: modelName,
+func NewOpenAIModel(modelName string, cfg openai.ClientConfig, opts ...Option) *OpenAIModel {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	o := &OpenAIModel{ModelName: modelName}
+	for _, opt := range opts {
+		opt(o)
 	}
+	cfg.HTTPClient = withDefaultHeaders(cfg.HTTPClient, o.defaultHeaders)
+	var doer openai.HTTPDoer = rawResponseDoer{doer: chatBodyDoer{doer: modelsPageDoer{doer: cfg.HTTPClient}}}
+	doer = streamIdleDoer{doer: doer, model: o}
+	cfg.HTTPClient = rateLimitDoer{doer: doer, model: o}
+	if o.baseURL != "" {
+		cfg.BaseURL = o.baseURL
+		if !o.rawBaseURL {
+			cfg.BaseURL = normalizeBaseURL(cfg.BaseURL)
+		}
+	}
+	o.Client = openai.NewClientWithConfig(cfg)
+	return o
 }
 
 // Name implements model.LLM.
@@ -44,6 +432,14 @@ func (o *OpenAIModel) Name() string {
 	return o.ModelName
 }
 
+// OpenAIClient returns the go-openai client chat completions are sent
+// through, for endpoints this package does not wrap. It is the Client field;
+// the method lets code holding a model.LLM reach the client through an
+// interface without depending on the concrete type.
+func (o *OpenAIModel) OpenAIClient() *openai.Client {
+	return o.Client
+}
+
 // GenerateContent implements model.LLM.
 func (o *OpenAIModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	if stream {
@@ -52,25 +448,82 @@ func (o *OpenAIModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	return o.generate(ctx, req)
 }
 
+// BuildRequest returns the chat completion request a non-streaming
+// GenerateContent call would send for req, without calling the API. It runs
+// the same conversion, OnRequest hook and, if enabled, validation. Fields
+// from ExtraBody and ContextWithExtraBody are merged into the JSON body when
+// sending and are not part of the returned request.
+func (o *OpenAIModel) BuildRequest(ctx context.Context, req *model.LLMRequest) (openai.ChatCompletionRequest, error) {
+	return o.buildRequest(ctx, req, false)
+}
+
+// buildRequest converts req into the chat completion request to send,
+// streaming if stream is set, and passes it through OnRequest and validation.
+func (o *OpenAIModel) buildRequest(ctx context.Context, req *model.LLMRequest, stream bool) (openai.ChatCompletionRequest, error) {
+	openaiReq, err := o.toOpenAIChatCompletionRequest(ctx, req)
+	if err != nil {
+		return openai.ChatCompletionRequest{}, err
+	}
+	if stream {
+		openaiReq.Stream = true
+		if o.StreamUsage {
+			openaiReq.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+		}
+	}
+	if o.OnRequest != nil {
+		o.OnRequest(ctx, &openaiReq)
+	}
+	if o.ValidateRequests {
+		if err := validateRequest(openaiReq); err != nil {
+			return openai.ChatCompletionRequest{}, err
+		}
+	}
+	return openaiReq, nil
+}
+
 func (o *OpenAIModel) generate(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
-		openaiReq, err := toOpenAIChatCompletionRequest(req, o.ModelName)
+		yield = o.logResponses(ctx, false, time.Now(), o.observeResponses(ctx, yield))
+		ctx, yield = o.captureRawResponses(ctx, yield)
+
+		openaiReq, err := o.buildRequest(ctx, req, false)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
-
-		resp, err := o.Client.CreateChatCompletion(ctx, openaiReq)
+		o.logRequest(ctx, openaiReq)
+		ctx, cancel := o.requestContext(contextWithExtraBody(ctx, o.extraBody(ctx, req)))
+		defer cancel()
+
+		var resp openai.ChatCompletionResponse
+		if n := candidateCount(o.generationConfig(req)); o.FanOutCandidates && n > 1 {
+			resp, err = o.fanOutChatCompletion(ctx, openaiReq, n)
+		} else {
+			resp, err = o.Client.CreateChatCompletion(ctx, openaiReq)
+		}
 		if err != nil {
 			yield(nil, err)
 			return
 		}
 
-		llmResp, err := convertChatCompletionResponse(&resp)
+		llmResp, err := convertChatCompletionResponse(&resp, o.finishReason)
+		if err == nil {
+			err = o.refusalError(llmResp)
+		}
 		if err != nil {
 			yield(nil, err)
 			return
 		}
+		for _, choice := range resp.Choices {
+			for _, toolCall := range choice.Message.ToolCalls {
+				o.preserveToolArguments(toolCall.ID, toolCall.Function.Arguments)
+			}
+		}
+		o.applyCandidateGroups(llmResp)
+		if err := o.truncationError(llmResp); err != nil {
+			yield(nil, err)
+			return
+		}
 
 		yield(llmResp, nil)
 	}
@@ -78,157 +531,452 @@ func (o *OpenAIModel) generate(ctx context.Context, req *model.LLMRequest) iter.
 
 func (o *OpenAIModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
-		openaiReq, err := toOpenAIChatCompletionRequest(req, o.ModelName)
+		yield = o.logResponses(ctx, true, time.Now(), o.observeResponses(ctx, yield))
+		ctx, yield = o.captureRawResponses(ctx, yield)
+
+		openaiReq, err := o.buildRequest(ctx, req, true)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
-		openaiReq.Stream = true
+		o.logRequest(ctx, openaiReq)
+		ctx, cancel := o.streamContext(contextWithExtraBody(ctx, o.extraBody(ctx, req)))
+		defer cancel()
+
+		start := time.Now()
+		o.streamWithReconnects(ctx, func() (chatCompletionStream, error) {
+			stream, err := o.createChatCompletionStream(ctx, cancel, openaiReq)
+			if err != nil {
+				return nil, err
+			}
+			return o.observeFirstToken(stream, start), nil
+		}, yield)
+	}
+}
 
-		stream, err := o.Client.CreateChatCompletionStream(ctx, openaiReq)
+// observeResponses wraps yield so that OnResponse sees every error and every
+// non-partial response before it is passed on.
+func (o *OpenAIModel) observeResponses(ctx context.Context, yield func(*model.LLMResponse, error) bool) func(*model.LLMResponse, error) bool {
+	if o.OnResponse == nil {
+		return yield
+	}
+	return func(resp *model.LLMResponse, err error) bool {
+		if err != nil || !resp.Partial {
+			o.OnResponse(ctx, resp, err)
+		}
+		return yield(resp, err)
+	}
+}
+
+// chatCompletionStream is the subset of *openai.ChatCompletionStream used to
+// consume streamed chat completion chunks.
+type chatCompletionStream interface {
+	Recv() (openai.ChatCompletionStreamResponse, error)
+	Close() error
+}
+
+// readStream consumes stream, yielding partial responses as deltas arrive and a
+// final aggregated response once the stream ends. It stops as soon as yield
+// returns false or ctx is done, and closes stream before returning so the
+// connection is released promptly.
+func (o *OpenAIModel) readStream(ctx context.Context, stream chatCompletionStream, yield func(*model.LLMResponse, error) bool) {
+	defer stream.Close()
+
+	// Aggregate the streaming chunks per choice index. Partial responses are
+	// only yielded for the first choice; the others are reported as candidates
+	// on the final response.
+	candidates := map[int]*streamCandidate{}
+	var usageMetadata *genai.GenerateContentResponseUsageMetadata
+	var usage *openai.Usage
+	var modelVersion, systemFingerprint string
+	discard := func(*model.LLMResponse) bool { return true }
+	emit := o.estimateStreamUsage(func(resp *model.LLMResponse) bool { return yield(resp, nil) })
+	// On cancellation, report what was received, as those tokens may already
+	// be billed, before the context error
+	cancelled := func(err error) {
+		resp := o.interruptedResponse(candidates[0], usageMetadata)
+		if yield(resp, nil) {
+			yield(nil, err)
+		}
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			cancelled(err)
+			return
+		}
+		chunk, err := stream.Recv()
 		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				cancelled(ctxErr)
+				return
+			}
 			yield(nil, err)
 			return
 		}
-		defer stream.Close()
 
-		// Aggregate the streaming chunks
-		aggregatedContent := &genai.Content{
-			Role:  "model",
-			Parts: []*genai.Part{},
+		if chunk.Model != "" {
+			modelVersion = chunk.Model
+		}
+		if chunk.SystemFingerprint != "" {
+			systemFingerprint = chunk.SystemFingerprint
 		}
-		var finishReason genai.FinishReason
-		var usageMetadata *genai.GenerateContentResponseUsageMetadata
 
-		// Track tool calls by index to properly aggregate them across chunks
-		toolCallsMap := make(map[int]*toolCallBuilder)
+		// With include_usage, usage arrives on a final chunk without choices
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+			usageMetadata = convertUsage(*chunk.Usage)
+		}
 
-		lastPartIsText := false
-		for {
-			chunk, err := stream.Recv()
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					break
-				}
-				yield(nil, err)
+		for _, choice := range chunk.Choices {
+			candidate, ok := candidates[choice.Index]
+			if !ok {
+				candidate = newStreamCandidate()
+				candidates[choice.Index] = candidate
+			}
+			partial := discard
+			if choice.Index == 0 {
+				partial = emit
+			}
+			if !o.addStreamChoice(candidate, choice, partial) {
 				return
 			}
-
-			if len(chunk.Choices) == 0 {
-				continue
+			if o.AbortOnContentFilter && choice.FinishReason == openai.FinishReasonContentFilter {
+				yield(nil, o.safetyError(candidate))
+				return
 			}
+		}
+	}
 
-			choice := chunk.Choices[0]
+	primary, ok := candidates[0]
+	if !ok {
+		primary = newStreamCandidate()
+	}
+	// Flush the trailing text that never reached a sentence boundary
+	if primary.pendingText != "" && !emit(partialTextResponse(primary.content.Role, primary.pendingText)) {
+		return
+	}
 
-			// Handle delta content
-			if choice.Delta.Content != "" {
-				part := &genai.Part{Text: choice.Delta.Content}
-				if lastPartIsText {
-					aggregatedContent.Parts[len(aggregatedContent.Parts)-1].Text += part.Text
-				} else {
-					aggregatedContent.Parts = append(aggregatedContent.Parts, part)
-				}
+	indices := make([]int, 0, len(candidates))
+	for idx, candidate := range candidates {
+		o.finishStreamCandidate(candidate)
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
 
-				lastPartIsText = true
-				// Yield partial response
-				llmResp := &model.LLMResponse{
-					Content:      &genai.Content{Role: "model", Parts: []*genai.Part{part}},
-					Partial:      true,
-					TurnComplete: false,
-				}
-				if !yield(llmResp, nil) {
-					return
-				}
-			} else {
-				lastPartIsText = false
-			}
-
-			// Handle tool calls in delta - aggregate across chunks
-			if len(choice.Delta.ToolCalls) > 0 {
-				for _, toolCall := range choice.Delta.ToolCalls {
-					// Use Index if available, otherwise use 0 as default
-					idx := 0
-					if toolCall.Index != nil {
-						idx = *toolCall.Index
-					}
-
-					builder, exists := toolCallsMap[idx]
-					if !exists {
-						builder = &toolCallBuilder{
-							id:   toolCall.ID,
-							name: toolCall.Function.Name,
-							args: "",
-						}
-						toolCallsMap[idx] = builder
-					}
-
-					// Update fields if present
-					if toolCall.ID != "" {
-						builder.id = toolCall.ID
-					}
-					if toolCall.Function.Name != "" {
-						builder.name = toolCall.Function.Name
-					}
-					if toolCall.Function.Arguments != "" {
-						builder.args += toolCall.Function.Arguments
-					}
-				}
-			}
+	// A stream of only empty or heartbeat chunks carries no answer at all
+	if len(primary.content.Parts) == 0 && primary.refusal == "" && primary.finishReason == "" {
+		yield(nil, ErrNoChoicesInResponse)
+		return
+	}
 
-			// Capture finish reason
-			if choice.FinishReason != "" {
-				finishReason = convertFinishReason(string(choice.FinishReason))
+	// Send final complete response
+	finalResp := &model.LLMResponse{
+		Content:       primary.content,
+		UsageMetadata: usageMetadata,
+		FinishReason:  primary.finishReason,
+		Partial:       false,
+		TurnComplete:  true,
+	}
+	finalResp.LogprobsResult, finalResp.AvgLogprobs = convertLogprobs(primary.logprobs)
+	if len(primary.safetyRatings) > 0 {
+		setCustomMetadata(finalResp, SafetyRatingsMetadataKey, primary.safetyRatings)
+	}
+	applyPredictionUsage(finalResp, usage)
+	applyModelVersion(finalResp, modelVersion, systemFingerprint)
+	applyToolCallsFinishReason(finalResp, primary.rawFinishReason)
+	applyRefusal(finalResp, primary.refusal)
+
+	if len(indices) > 1 {
+		genaiCandidates := make([]*genai.Candidate, 0, len(indices))
+		for _, idx := range indices {
+			candidate := candidates[idx]
+			genaiCandidate := &genai.Candidate{
+				Content:       candidate.content,
+				FinishReason:  candidate.finishReason,
+				Index:         int32(idx),
+				SafetyRatings: candidate.safetyRatings,
 			}
+			genaiCandidate.LogprobsResult, genaiCandidate.AvgLogprobs = convertLogprobs(candidate.logprobs)
+			genaiCandidates = append(genaiCandidates, genaiCandidate)
+		}
+		setCustomMetadata(finalResp, CandidatesMetadataKey, genaiCandidates)
+		o.applyCandidateGroups(finalResp)
+	}
 
-			// Capture usage metadata if available
-			if chunk.Usage != nil {
-				usageMetadata = &genai.GenerateContentResponseUsageMetadata{
-					PromptTokenCount:     int32(chunk.Usage.PromptTokens),
-					CandidatesTokenCount: int32(chunk.Usage.CompletionTokens),
-					TotalTokenCount:      int32(chunk.Usage.TotalTokens),
-				}
-			}
+	if err := o.refusalError(finalResp); err != nil {
+		yield(nil, err)
+		return
+	}
+	if err := o.truncationError(finalResp); err != nil {
+		yield(nil, err)
+		return
+	}
+	yield(finalResp, nil)
+}
+
+// interruptedResponse returns the final response of a stream cancelled
+// before it ended, carrying the content of candidate received so far and the
+// usage reported, which is zero when the stream ended before usage arrived.
+// It is marked Interrupted with FinishReasonOther.
+func (o *OpenAIModel) interruptedResponse(candidate *streamCandidate, usage *genai.GenerateContentResponseUsageMetadata) *model.LLMResponse {
+	if candidate == nil {
+		candidate = newStreamCandidate()
+	}
+	o.finishStreamCandidate(candidate)
+	if usage == nil {
+		usage = &genai.GenerateContentResponseUsageMetadata{}
+	}
+	return &model.LLMResponse{
+		Content:       candidate.content,
+		UsageMetadata: usage,
+		FinishReason:  genai.FinishReasonOther,
+		TurnComplete:  true,
+		Interrupted:   true,
+	}
+}
+
+// streamCandidate aggregates the streamed deltas of a single choice.
+type streamCandidate struct {
+	content         *genai.Content
+	finishReason    genai.FinishReason
+	rawFinishReason openai.FinishReason
+	logprobs        []openai.LogProb
+	safetyRatings   []*genai.SafetyRating
+	refusal         string
+
+	// Set once a delta has given the role of content
+	hasRole bool
+
+	// Track tool calls by index to properly aggregate them across chunks.
+	// Calls without an index get synthetic ones counting down from -1, so
+	// they never meet the non-negative indexes backends send.
+	toolCalls        map[int]*toolCallBuilder
+	lastToolCallIdx  int
+	nextSyntheticIdx int
+
+	lastPartIsText    bool
+	lastPartIsThought bool
+	// Text held back until a sentence boundary when StreamSentences is set
+	pendingText string
+}
+
+func newStreamCandidate() *streamCandidate {
+	return &streamCandidate{
+		content:          &genai.Content{Role: "model", Parts: []*genai.Part{}},
+		toolCalls:        make(map[int]*toolCallBuilder),
+		lastToolCallIdx:  -1,
+		nextSyntheticIdx: -1,
+	}
+}
+
+// addStreamChoice aggregates the delta of choice into candidate, passing
+// partial responses to emit. It returns false as soon as emit does.
+func (o *OpenAIModel) addStreamChoice(candidate *streamCandidate, choice openai.ChatCompletionStreamChoice, emit func(*model.LLMResponse) bool) bool {
+	content := candidate.content
+
+	// Take the role from the first delta that names one; later role changes
+	// are ignored
+	if !candidate.hasRole && choice.Delta.Role != "" {
+		candidate.hasRole = true
+		content.Role = convertRoleFromOpenAI(choice.Delta.Role)
+	}
+
+	// Handle reasoning deltas from DeepSeek-style endpoints as thought parts
+	if choice.Delta.ReasoningContent != "" {
+		if candidate.lastPartIsThought {
+			content.Parts[len(content.Parts)-1].Text += choice.Delta.ReasoningContent
+		} else {
+			content.Parts = append(content.Parts, &genai.Part{Text: choice.Delta.ReasoningContent, Thought: true})
 		}
 
-		// Convert aggregated tool calls to parts
-		if len(toolCallsMap) > 0 {
-			// Sort by index to maintain order
-			indices := make([]int, 0, len(toolCallsMap))
-			for idx := range toolCallsMap {
-				indices = append(indices, idx)
-			}
-			// Simple bubble sort for small arrays
-			for i := 0; i < len(indices)-1; i++ {
-				for j := 0; j < len(indices)-i-1; j++ {
-					if indices[j] > indices[j+1] {
-						indices[j], indices[j+1] = indices[j+1], indices[j]
-					}
-				}
+		candidate.lastPartIsThought = true
+		if !emit(partialThoughtResponse(content.Role, choice.Delta.ReasoningContent)) {
+			return false
+		}
+	} else {
+		candidate.lastPartIsThought = false
+	}
+
+	// Handle delta content
+	if choice.Delta.Content != "" {
+		if candidate.lastPartIsText {
+			content.Parts[len(content.Parts)-1].Text += choice.Delta.Content
+		} else {
+			content.Parts = append(content.Parts, &genai.Part{Text: choice.Delta.Content})
+		}
+
+		candidate.lastPartIsText = true
+		text := choice.Delta.Content
+		if o.StreamSentences {
+			text, candidate.pendingText = splitSentences(candidate.pendingText+text, o.sentenceDelimiters())
+		}
+		// Yield partial response
+		if text != "" && !emit(partialTextResponse(content.Role, text)) {
+			return false
+		}
+	} else {
+		candidate.lastPartIsText = false
+	}
+
+	// Handle tool calls in delta - aggregate across chunks
+	for _, toolCall := range choice.Delta.ToolCalls {
+		// Use Index if available. Without one, a chunk carrying a new ID starts
+		// a new call under a synthetic index so parallel calls don't collide,
+		// and a chunk without an ID continues the previous call.
+		var idx int
+		switch {
+		case toolCall.Index != nil:
+			idx = *toolCall.Index
+		case toolCall.ID == "" && candidate.toolCalls[candidate.lastToolCallIdx] != nil:
+			idx = candidate.lastToolCallIdx
+		default:
+			idx = candidate.toolCallIndexByID(toolCall.ID)
+		}
+		candidate.lastToolCallIdx = idx
+
+		builder, exists := candidate.toolCalls[idx]
+		if !exists {
+			builder = &toolCallBuilder{
+				id:   toolCall.ID,
+				name: toolCall.Function.Name,
+				args: "",
 			}
+			candidate.toolCalls[idx] = builder
+		}
+
+		// Update fields if present
+		if toolCall.ID != "" {
+			builder.id = toolCall.ID
+		}
+		if toolCall.Function.Name != "" {
+			builder.name = toolCall.Function.Name
+		}
+		if toolCall.Function.Arguments != "" {
+			builder.args += toolCall.Function.Arguments
+		}
 
-			for _, idx := range indices {
-				builder := toolCallsMap[idx]
-				part := &genai.Part{
+		if o.StreamToolCallDeltas && builder.name != "" {
+			willContinue := true
+			llmResp := &model.LLMResponse{
+				Content: &genai.Content{Role: content.Role, Parts: []*genai.Part{{
 					FunctionCall: &genai.FunctionCall{
-						ID:   builder.id,
-						Name: builder.name,
-						Args: parseJSONArgs(builder.args),
+						ID:           builder.id,
+						Name:         builder.name,
+						Args:         parseJSONArgs(builder.args),
+						WillContinue: &willContinue,
 					},
-				}
-				aggregatedContent.Parts = append(aggregatedContent.Parts, part)
+				}}},
+				Partial:      true,
+				TurnComplete: false,
 			}
+			if !emit(llmResp) {
+				return false
+			}
+		}
+	}
+
+	// Capture token logprobs
+	if choice.Logprobs != nil {
+		for _, token := range choice.Logprobs.Content {
+			candidate.logprobs = append(candidate.logprobs, convertStreamTokenLogprob(token))
 		}
+	}
+
+	// Capture refusal deltas, which arrive instead of content
+	candidate.refusal += choice.Delta.Refusal
+
+	// Capture content filter results, which Azure repeats as they update
+	if ratings := convertContentFilterResults(choice.ContentFilterResults); len(ratings) > 0 {
+		candidate.safetyRatings = ratings
+	}
+
+	// Capture finish reason
+	if choice.FinishReason != "" {
+		candidate.rawFinishReason = choice.FinishReason
+		candidate.finishReason = o.finishReason(string(choice.FinishReason))
+	}
+	return true
+}
 
-		// Send final complete response
-		finalResp := &model.LLMResponse{
-			Content:       aggregatedContent,
-			UsageMetadata: usageMetadata,
-			FinishReason:  finishReason,
-			Partial:       false,
-			TurnComplete:  true,
+// finishStreamCandidate completes candidate once the stream has ended,
+// appending its aggregated tool calls as function call parts.
+func (o *OpenAIModel) finishStreamCandidate(candidate *streamCandidate) {
+	// Sort by index to maintain order, placing calls with synthetic indexes
+	// last, in the order they started
+	indices := make([]int, 0, len(candidate.toolCalls))
+	for idx := range candidate.toolCalls {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		a, b := indices[i], indices[j]
+		if (a < 0) != (b < 0) {
+			return a >= 0
+		}
+		if a < 0 {
+			return a > b
 		}
-		yield(finalResp, nil)
+		return a < b
+	})
+
+	for _, idx := range indices {
+		builder := candidate.toolCalls[idx]
+		candidate.content.Parts = append(candidate.content.Parts, &genai.Part{
+			FunctionCall: &genai.FunctionCall{
+				ID:   builder.id,
+				Name: builder.name,
+				Args: parseJSONArgs(builder.args),
+			},
+		})
+		o.preserveToolArguments(builder.id, builder.args)
+	}
+
+	// Local servers may end a stream without ever sending a finish reason
+	if o.LenientStreamEnd && candidate.finishReason == "" && len(candidate.content.Parts) > 0 {
+		candidate.finishReason = genai.FinishReasonStop
+	}
+}
+
+// partialTextResponse returns a partial streaming response carrying text
+// under role.
+func partialTextResponse(role, text string) *model.LLMResponse {
+	return &model.LLMResponse{
+		Content:      &genai.Content{Role: role, Parts: []*genai.Part{{Text: text}}},
+		Partial:      true,
+		TurnComplete: false,
+	}
+}
+
+// partialThoughtResponse returns a partial streaming response carrying
+// reasoning text as a thought part under role.
+func partialThoughtResponse(role, text string) *model.LLMResponse {
+	resp := partialTextResponse(role, text)
+	resp.Content.Parts[0].Thought = true
+	return resp
+}
+
+// defaultSentenceDelimiters end a sentence when StreamSentences is set and no
+// SentenceDelimiters are configured.
+const defaultSentenceDelimiters = ".!?\n。！？"
+
+func (o *OpenAIModel) sentenceDelimiters() string {
+	if o.SentenceDelimiters != "" {
+		return o.SentenceDelimiters
+	}
+	return defaultSentenceDelimiters
+}
+
+// splitSentences splits text after the last rune that is in delimiters,
+// returning the complete sentences and the unterminated remainder.
+func splitSentences(text, delimiters string) (sentences, rest string) {
+	i := strings.LastIndexAny(text, delimiters)
+	if i < 0 {
+		return "", text
 	}
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return text[:i+size], text[i+size:]
 }
 
 // toolCallBuilder helps aggregate tool call information across streaming chunks
@@ -238,22 +986,48 @@ type toolCallBuilder struct {
 	args string
 }
 
-func toOpenAIChatCompletionRequest(req *model.LLMRequest, modelName string) (openai.ChatCompletionRequest, error) {
+// toolCallIndexByID returns the index of the tool call with the given ID, or
+// a new synthetic index if there is none.
+func (c *streamCandidate) toolCallIndexByID(id string) int {
+	for idx, builder := range c.toolCalls {
+		if id != "" && builder.id == id {
+			return idx
+		}
+	}
+	idx := c.nextSyntheticIdx
+	c.nextSyntheticIdx--
+	return idx
+}
+
+func (o *OpenAIModel) toOpenAIChatCompletionRequest(ctx context.Context, req *model.LLMRequest) (openai.ChatCompletionRequest, error) {
 	openaiMessages := make([]openai.ChatCompletionMessage, 0, len(req.Contents))
-	for _, content := range req.Contents {
-		msgs, err := toOpenAIChatCompletionMessage(content)
+	for _, content := range linkFunctionResponses(req.Contents, o.textSeparator()) {
+		msgs, err := o.toOpenAIChatCompletionMessage(content)
 		if err != nil {
 			return openai.ChatCompletionRequest{}, err
 		}
-		openaiMessages = append(openaiMessages, msgs...)
+		setMessageName(msgs, o.messageName(content))
+		for _, msg := range msgs {
+			if o.DropEmptyAssistantMessages && isEmptyAssistantMessage(msg) {
+				continue
+			}
+			openaiMessages = append(openaiMessages, msg)
+		}
+	}
+	openaiMessages = reorderToolResponses(openaiMessages)
+	if o.MergeConsecutive {
+		openaiMessages = mergeConsecutiveMessages(openaiMessages, o.textSeparator())
 	}
 
+	cfg := o.generationConfig(req)
 	openaiReq := openai.ChatCompletionRequest{
-		Model:    modelName,
-		Messages: openaiMessages,
+		Model:      o.ModelName,
+		Messages:   openaiMessages,
+		User:       o.user(ctx),
+		Prediction: prediction(ctx),
 	}
-	if req.Config.ThinkingConfig != nil {
-		switch req.Config.ThinkingConfig.ThinkingLevel {
+	if cfg.ThinkingConfig != nil {
+		switch cfg.ThinkingConfig.ThinkingLevel {
 		case genai.ThinkingLevelLow:
 			openaiReq.ReasoningEffort = "low"
 		case genai.ThinkingLevelHigh:
@@ -262,20 +1036,20 @@ func toOpenAIChatCompletionRequest(req *model.LLMRequest, modelName string) (ope
 			openaiReq.ReasoningEffort = "medium"
 		}
 	}
-	if req.Config.ResponseJsonSchema != nil {
+	if cfg.ResponseJsonSchema != nil {
 		// TODO: convert schema to openai schema
 		return openai.ChatCompletionRequest{}, fmt.Errorf("response json schema is not supported")
 		/*
 			openaiReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
 				Type:       openai.ChatCompletionResponseFormatTypeJSONObject,
-				JSONSchema: req.Config.ResponseJsonSchema,
+				JSONSchema: cfg.ResponseJsonSchema,
 			}
 		*/
 	}
 
 	// Convert tools if present
-	if req.Config != nil && len(req.Config.Tools) > 0 {
-		tools, err := convertTools(req.Config.Tools)
+	if genaiTools := o.allowedTools(ctx, cfg.Tools); len(genaiTools) > 0 {
+		tools, err := convertTools(genaiTools, o.schemaOptions())
 		if err != nil {
 			return openai.ChatCompletionRequest{}, err
 		}
@@ -283,57 +1057,189 @@ func toOpenAIChatCompletionRequest(req *model.LLMRequest, modelName string) (ope
 	}
 
 	// Apply config settings
-	if req.Config != nil {
-		if req.Config.Temperature != nil {
-			openaiReq.Temperature = *req.Config.Temperature
-		}
-		if req.Config.MaxOutputTokens > 0 {
-			openaiReq.MaxTokens = int(req.Config.MaxOutputTokens)
+	if temperature := temperature(ctx, cfg); temperature != nil {
+		openaiReq.Temperature = *temperature
+	}
+	if maxTokens := o.maxTokens(cfg); maxTokens > 0 {
+		if capabilitiesForModel(o.ModelName).maxCompletionTokens {
+			openaiReq.MaxCompletionTokens = maxTokens
+		} else {
+			openaiReq.MaxTokens = maxTokens
 		}
-		if req.Config.TopP != nil {
-			openaiReq.TopP = *req.Config.TopP
+	}
+	if cfg.TopP != nil {
+		openaiReq.TopP = *cfg.TopP
+	}
+	if len(cfg.StopSequences) > 0 {
+		openaiReq.Stop = cfg.StopSequences
+	}
+	if n := candidateCount(cfg); n > 1 && !o.FanOutCandidates {
+		openaiReq.N = n
+	}
+	if len(o.LogitBias) > 0 {
+		for token, bias := range o.LogitBias {
+			if bias < -100 || bias > 100 {
+				return openai.ChatCompletionRequest{}, fmt.Errorf("logit bias %d for token %q is outside [-100, 100]", bias, token)
+			}
 		}
-		if len(req.Config.StopSequences) > 0 {
-			openaiReq.Stop = req.Config.StopSequences
+		openaiReq.LogitBias = o.LogitBias
+	}
+	switch o.ImageDetail {
+	case "", openai.ImageURLDetailAuto, openai.ImageURLDetailLow, openai.ImageURLDetailHigh:
+	default:
+		return openai.ChatCompletionRequest{}, fmt.Errorf("unknown image detail %q", o.ImageDetail)
+	}
+	openaiReq.Store = o.Store
+	switch o.ServiceTier {
+	case "", openai.ServiceTierAuto, openai.ServiceTierDefault, openai.ServiceTierFlex, openai.ServiceTierPriority:
+		openaiReq.ServiceTier = o.ServiceTier
+	default:
+		return openai.ChatCompletionRequest{}, fmt.Errorf("unknown service tier %q", o.ServiceTier)
+	}
+	if len(o.Metadata) > 0 {
+		if err := validateMetadata(o.Metadata); err != nil {
+			return openai.ChatCompletionRequest{}, err
 		}
+		openaiReq.Metadata = o.Metadata
+	}
+	if cfg.ResponseLogprobs || cfg.Logprobs != nil {
+		openaiReq.LogProbs = true
+	}
+	if cfg.Logprobs != nil {
+		openaiReq.TopLogProbs = int(*cfg.Logprobs)
+	}
 
-		// Handle system instruction
-		if req.Config.SystemInstruction != nil {
-			systemMsg := openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: extractTextFromContent(req.Config.SystemInstruction),
-			}
+	// Handle system instruction
+	if cfg.SystemInstruction != nil {
+		systemMsg, err := o.systemMessage(cfg.SystemInstruction)
+		if err != nil {
+			return openai.ChatCompletionRequest{}, err
+		}
+		// Merge into a leading system turn from Contents, as some backends
+		// reject more than one system message
+		if len(openaiMessages) > 0 && openaiMessages[0].Role == openai.ChatMessageRoleSystem {
+			openaiMessages[0] = mergeSystemMessages(systemMsg, openaiMessages[0])
+		} else {
 			openaiMessages = append([]openai.ChatCompletionMessage{systemMsg}, openaiMessages...)
-			openaiReq.Messages = openaiMessages
 		}
+		openaiReq.Messages = openaiMessages
+	}
 
-		// Handle JSON mode
-		if req.Config.ResponseMIMEType == "application/json" {
-			openaiReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
-			}
+	// Handle JSON mode, with Structured Outputs when a schema is given
+	if cfg.ResponseSchema != nil && o.ResponseSchemaFormat != ResponseSchemaJSONObject {
+		format, err := o.responseSchemaFormat(cfg.ResponseSchema)
+		if err != nil {
+			return openai.ChatCompletionRequest{}, err
+		}
+		openaiReq.ResponseFormat = format
+	} else if cfg.ResponseSchema != nil || cfg.ResponseMIMEType == "application/json" {
+		openaiReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
 		}
 	}
 
 	return openaiReq, nil
 }
 
-func toOpenAIChatCompletionMessage(content *genai.Content) ([]openai.ChatCompletionMessage, error) {
+// responseSchemaFormat converts schema into a json_schema response format. It
+// is strict under ResponseSchemaStrict unless the schema cannot be made
+// strict.
+func (o *OpenAIModel) responseSchemaFormat(schema *genai.Schema) (*openai.ChatCompletionResponseFormat, error) {
+	converted, err := convertSchema(schema, o.schemaOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert response schema: %w", err)
+	}
+	strict := false
+	if o.ResponseSchemaFormat == ResponseSchemaStrict {
+		// strictJSONSchema rewrites in place and may fail halfway, so it runs
+		// on a second conversion that is only used if it succeeds
+		candidate, _ := convertSchema(schema, o.schemaOptions())
+		if strictJSONSchema(candidate, o.NullableStyle) == nil {
+			converted, strict = candidate, true
+		}
+	}
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   "response",
+			Schema: jsonSchema(converted),
+			Strict: strict,
+		},
+	}, nil
+}
+
+// maxTokens returns the completion token limit to send for cfg, or 0 to send
+// none.
+func (o *OpenAIModel) maxTokens(cfg *genai.GenerateContentConfig) int {
+	if cfg.MaxOutputTokens > 0 {
+		return int(cfg.MaxOutputTokens)
+	}
+	return max(o.MaxTokens, 0)
+}
+
+// systemMessage converts a system instruction into a system message. Only its
+// text is kept unless MultimodalSystemInstruction is set, in which case it is
+// converted like any other content.
+func (o *OpenAIModel) systemMessage(instruction *genai.Content) (openai.ChatCompletionMessage, error) {
+	if !o.MultimodalSystemInstruction {
+		return openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: extractTextFromContent(instruction, o.textSeparator()),
+		}, nil
+	}
+
+	msgs, err := o.toOpenAIChatCompletionMessage(instruction)
+	if err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+	systemMsg := msgs[len(msgs)-1]
+	systemMsg.Role = openai.ChatMessageRoleSystem
+	return systemMsg, nil
+}
+
+// mergeSystemMessages combines two system messages into one carrying first's
+// content followed by second's.
+func mergeSystemMessages(first, second openai.ChatCompletionMessage) openai.ChatCompletionMessage {
+	merged := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem}
+	if len(first.MultiContent) == 0 && len(second.MultiContent) == 0 {
+		merged.Content = strings.TrimSpace(first.Content + "\n\n" + second.Content)
+		return merged
+	}
+	for _, msg := range []openai.ChatCompletionMessage{first, second} {
+		if len(msg.MultiContent) > 0 {
+			merged.MultiContent = append(merged.MultiContent, msg.MultiContent...)
+		} else if msg.Content != "" {
+			merged.MultiContent = append(merged.MultiContent, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeText,
+				Text: msg.Content,
+			})
+		}
+	}
+	return merged
+}
+
+func (o *OpenAIModel) toOpenAIChatCompletionMessage(content *genai.Content) ([]openai.ChatCompletionMessage, error) {
+	content, reasoning := splitThoughts(content)
+	msgs, err := o.toOpenAIChatCompletionMessageParts(content)
+	if err != nil {
+		return nil, err
+	}
+	o.setReasoningContent(msgs, reasoning)
+	return msgs, nil
+}
+
+// toOpenAIChatCompletionMessageParts converts content without thought parts
+// into messages.
+func (o *OpenAIModel) toOpenAIChatCompletionMessageParts(content *genai.Content) ([]openai.ChatCompletionMessage, error) {
 	// Special: if all parts are function responses, return multi tool message
 	toolRespMessages := make([]openai.ChatCompletionMessage, 0)
 	skipIdx := 0
 	for idx, part := range content.Parts {
 		if part.FunctionResponse != nil {
-			openaiMsg := openai.ChatCompletionMessage{
-				Role:       openai.ChatMessageRoleTool,
-				ToolCallID: part.FunctionResponse.ID,
-			}
-			// Function responses become tool messages
-			responseJSON, err := json.Marshal(part.FunctionResponse.Response)
+			openaiMsg, err := o.toolResponseMessage(part.FunctionResponse)
 			if err != nil {
-				return nil, fmt.Errorf("failed to marshal function response: %w", err)
+				return nil, err
 			}
-			openaiMsg.Content = string(responseJSON)
 			toolRespMessages = append(toolRespMessages, openaiMsg)
 			skipIdx = idx + 1
 			continue
@@ -350,7 +1256,7 @@ func toOpenAIChatCompletionMessage(content *genai.Content) ([]openai.ChatComplet
 	}
 
 	// Simple case: single text part
-	if len(parts) == 1 && parts[0].Text != "" {
+	if !o.StructuredContent && len(parts) == 1 && hasText(parts[0]) && !(o.PromoteDataURIImages && dataURIImagePattern.MatchString(parts[0].Text)) {
 		openaiMsg.Content = parts[0].Text
 		return []openai.ChatCompletionMessage{openaiMsg}, nil
 	}
@@ -361,60 +1267,75 @@ func toOpenAIChatCompletionMessage(content *genai.Content) ([]openai.ChatComplet
 	var multiContent []openai.ChatMessagePart
 
 	for _, part := range parts {
-		if part.Text != "" {
-			if len(content.Parts) == 1 {
-				textContent = part.Text
+		text := part.Text
+		if !hasText(part) {
+			text = codeExecutionText(part)
+		}
+		if hasText(part) && o.PromoteDataURIImages && dataURIImagePattern.MatchString(part.Text) {
+			multiContent = append(multiContent, splitDataURIImages(part.Text, o.imageDetail(part))...)
+		} else if text != "" {
+			if len(content.Parts) == 1 && !o.StructuredContent {
+				textContent = text
 			} else {
 				multiContent = append(multiContent, openai.ChatMessagePart{
 					Type: openai.ChatMessagePartTypeText,
-					Text: part.Text,
+					Text: text,
 				})
 			}
 		}
 
 		if part.FunctionCall != nil {
-			argsJSON, err := json.Marshal(part.FunctionCall.Args)
+			args, err := o.toolCallArguments(part)
 			if err != nil {
-				return nil, fmt.Errorf("failed to marshal function args: %w", err)
+				return nil, err
 			}
 			toolCall := openai.ToolCall{
 				ID:   part.FunctionCall.ID,
 				Type: openai.ToolTypeFunction,
 				Function: openai.FunctionCall{
 					Name:      part.FunctionCall.Name,
-					Arguments: string(argsJSON),
+					Arguments: args,
 				},
 			}
 			toolCalls = append(toolCalls, toolCall)
 		}
 
 		if part.FunctionResponse != nil {
-			// Function responses become tool messages
-			responseJSON, err := json.Marshal(part.FunctionResponse.Response)
+			toolMsg, err := o.toolResponseMessage(part.FunctionResponse)
 			if err != nil {
-				return nil, fmt.Errorf("failed to marshal function response: %w", err)
+				return nil, err
 			}
-			openaiMsg.Role = openai.ChatMessageRoleTool
-			openaiMsg.Content = string(responseJSON)
-			openaiMsg.ToolCallID = part.FunctionResponse.ID
+			openaiMsg.Role = toolMsg.Role
+			openaiMsg.Content = toolMsg.Content
+			openaiMsg.MultiContent = toolMsg.MultiContent
+			openaiMsg.ToolCallID = toolMsg.ToolCallID
+			openaiMsg.Name = toolMsg.Name
+		}
+
+		if err := videoPartError(part); err != nil {
+			return nil, err
 		}
 
 		if part.InlineData != nil {
-			base64Data := base64.StdEncoding.EncodeToString(part.InlineData.Data)
-			imageURL := openai.ChatMessageImageURL{
-				URL:    fmt.Sprintf("data:%s;base64,%s", part.InlineData.MIMEType, base64Data),
-				Detail: openai.ImageURLDetailAuto,
-			}
-			multiContent = append(multiContent, openai.ChatMessagePart{
-				Type:     openai.ChatMessagePartTypeImageURL,
-				ImageURL: &imageURL,
-			})
+			imagePart, err := o.inlineImagePart(part.InlineData, o.imageDetail(part))
+			if err != nil {
+				return nil, err
+			}
+			multiContent = append(multiContent, imagePart)
 		}
 
 		if part.FileData != nil {
-
-			// OpenAI doesn't support file references directly, would need to download
-			// For now, we'll skip or add as text description
+			// Remote images are fetched by OpenAI itself. Other file references
+			// are not supported and are skipped
+			if url, ok := remoteImageURL(part.FileData); ok {
+				multiContent = append(multiContent, openai.ChatMessagePart{
+					Type: openai.ChatMessagePartTypeImageURL,
+					ImageURL: &openai.ChatMessageImageURL{
+						URL:    url,
+						Detail: o.imageDetail(part),
+					},
+				})
+			}
 		}
 	}
 
@@ -432,17 +1353,260 @@ func toOpenAIChatCompletionMessage(content *genai.Content) ([]openai.ChatComplet
 	return append(toolRespMessages, openaiMsg), nil
 }
 
-func convertChatCompletionResponse(resp *openai.ChatCompletionResponse) (*model.LLMResponse, error) {
+// toolResponseMessage converts a function response into the message carrying
+// the tool result back to the model.
+func (o *OpenAIModel) toolResponseMessage(resp *genai.FunctionResponse) (openai.ChatCompletionMessage, error) {
+	content, err := toolResponseContent(resp.Response, o.RawFunctionResponses)
+	if err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+
+	// Legacy endpoints match results to calls by function name
+	if o.Profile == ProfileLegacy {
+		return openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleFunction,
+			Name:    resp.Name,
+			Content: content,
+		}, nil
+	}
+	msg := openai.ChatCompletionMessage{
+		Role:       openai.ChatMessageRoleTool,
+		ToolCallID: resp.ID,
+		Content:    content,
+	}
+
+	// Images returned by the tool turn the content into text and image parts,
+	// as StructuredToolContent does for the text alone
+	images, err := o.toolResponseImages(resp.Parts)
+	if err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+	if len(images) > 0 || o.StructuredToolContent {
+		msg.MultiContent = append([]openai.ChatMessagePart{{
+			Type: openai.ChatMessagePartTypeText,
+			Text: content,
+		}}, images...)
+		msg.Content = ""
+	}
+	return msg, nil
+}
+
+// toolResponseContent renders a function response as tool message content.
+// ADK wraps results that are not objects under a lone "result" key; unless
+// raw is set, such a result is unwrapped so that a string is sent as plain
+// text and other values as their own JSON.
+func toolResponseContent(response map[string]any, raw bool) (string, error) {
+	var value any = response
+	if result, ok := response["result"]; ok && len(response) == 1 && !raw {
+		if text, ok := result.(string); ok {
+			return text, nil
+		}
+		value = result
+	}
+	data, err := marshalJSON(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal function response: %w", err)
+	}
+	return string(data), nil
+}
+
+// dataURIImagePattern matches a base64 encoded data:image URI.
+var dataURIImagePattern = regexp.MustCompile(`data:image/[A-Za-z0-9.+-]+;base64,[A-Za-z0-9+/]+=*`)
+
+// splitDataURIImages splits text around the data:image URIs it contains into
+// text and image_url parts with the given detail level. Blank text between
+// images is dropped.
+func splitDataURIImages(text string, detail openai.ImageURLDetail) []openai.ChatMessagePart {
+	var parts []openai.ChatMessagePart
+	addText := func(s string) {
+		if strings.TrimSpace(s) != "" {
+			parts = append(parts, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeText,
+				Text: s,
+			})
+		}
+	}
+
+	last := 0
+	for _, loc := range dataURIImagePattern.FindAllStringIndex(text, -1) {
+		addText(text[last:loc[0]])
+		parts = append(parts, openai.ChatMessagePart{
+			Type: openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{
+				URL:    text[loc[0]:loc[1]],
+				Detail: detail,
+			},
+		})
+		last = loc[1]
+	}
+	addText(text[last:])
+	return parts
+}
+
+// isEmptyAssistantMessage reports whether msg is an assistant message without
+// any content or tool calls.
+func isEmptyAssistantMessage(msg openai.ChatCompletionMessage) bool {
+	return msg.Role == openai.ChatMessageRoleAssistant &&
+		msg.Content == "" &&
+		len(msg.MultiContent) == 0 &&
+		len(msg.ToolCalls) == 0
+}
+
+// reorderToolResponses moves each tool message to directly follow the
+// assistant message whose tool call it answers, in tool call order, so that
+// history recorded out of order is accepted. Tool messages without a matching
+// tool call keep their position. Repeated tool call IDs are paired in order of
+// appearance.
+func reorderToolResponses(msgs []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	callCount := map[string]int{}
+	for _, msg := range msgs {
+		if msg.Role == openai.ChatMessageRoleAssistant {
+			for _, call := range msg.ToolCalls {
+				callCount[call.ID]++
+			}
+		}
+	}
+
+	// Queue matched tool messages by the tool call they answer
+	responses := map[string][]openai.ChatCompletionMessage{}
+	matched := make([]bool, len(msgs))
+	for i, msg := range msgs {
+		if msg.Role == openai.ChatMessageRoleTool && len(responses[msg.ToolCallID]) < callCount[msg.ToolCallID] {
+			responses[msg.ToolCallID] = append(responses[msg.ToolCallID], msg)
+			matched[i] = true
+		}
+	}
+	if len(responses) == 0 {
+		return msgs
+	}
+
+	reordered := make([]openai.ChatCompletionMessage, 0, len(msgs))
+	for i, msg := range msgs {
+		if matched[i] {
+			continue
+		}
+		reordered = append(reordered, msg)
+		if msg.Role != openai.ChatMessageRoleAssistant {
+			continue
+		}
+		for _, call := range msg.ToolCalls {
+			if queue := responses[call.ID]; len(queue) > 0 {
+				reordered = append(reordered, queue[0])
+				responses[call.ID] = queue[1:]
+			}
+		}
+	}
+	return reordered
+}
+
+// convertChatCompletionResponse converts resp into a response holding its
+// first choice. It orders resp.Choices by index first, so that candidates
+// follow genai's numbering from 0 even when a backend returns choices out of
+// order or repeats an index. finishReason maps the finish reasons of the
+// choices. OpenAI has no grounding or citation data, so those fields are left
+// nil.
+func convertChatCompletionResponse(resp *openai.ChatCompletionResponse, finishReason func(string) genai.FinishReason) (*model.LLMResponse, error) {
 	if len(resp.Choices) == 0 {
 		return nil, ErrNoChoicesInResponse
 	}
+	sort.SliceStable(resp.Choices, func(i, j int) bool {
+		return resp.Choices[i].Index < resp.Choices[j].Index
+	})
 
 	choice := resp.Choices[0]
+	content := convertChatCompletionChoice(choice)
+
+	var usageMetadata *genai.GenerateContentResponseUsageMetadata
+	if resp.Usage.TotalTokens > 0 {
+		usageMetadata = convertUsage(resp.Usage)
+	}
+
+	llmResp := &model.LLMResponse{
+		Content:       content,
+		UsageMetadata: usageMetadata,
+		FinishReason:  finishReason(string(choice.FinishReason)),
+		TurnComplete:  true,
+	}
+	if choice.LogProbs != nil {
+		llmResp.LogprobsResult, llmResp.AvgLogprobs = convertLogprobs(choice.LogProbs.Content)
+	}
+	if ratings := convertContentFilterResults(choice.ContentFilterResults); len(ratings) > 0 {
+		setCustomMetadata(llmResp, SafetyRatingsMetadataKey, ratings)
+	}
+	applyPredictionUsage(llmResp, &resp.Usage)
+	applyModelVersion(llmResp, resp.Model, resp.SystemFingerprint)
+	applyToolCallsFinishReason(llmResp, choice.FinishReason)
+	if resp.ServiceTier != "" {
+		setCustomMetadata(llmResp, ServiceTierMetadataKey, string(resp.ServiceTier))
+	}
+	applyRefusal(llmResp, choice.Message.Refusal)
+
+	if len(resp.Choices) > 1 {
+		candidates := make([]*genai.Candidate, 0, len(resp.Choices))
+		for i, choice := range resp.Choices {
+			candidateContent := content
+			if i > 0 {
+				candidateContent = convertChatCompletionChoice(choice)
+			}
+			candidate := &genai.Candidate{
+				Content:       candidateContent,
+				FinishReason:  finishReason(string(choice.FinishReason)),
+				Index:         int32(i),
+				SafetyRatings: convertContentFilterResults(choice.ContentFilterResults),
+			}
+			if choice.LogProbs != nil {
+				candidate.LogprobsResult, candidate.AvgLogprobs = convertLogprobs(choice.LogProbs.Content)
+			}
+			candidates = append(candidates, candidate)
+		}
+		setCustomMetadata(llmResp, CandidatesMetadataKey, candidates)
+	}
+
+	return llmResp, nil
+}
+
+// applyModelVersion records the model and system fingerprint a response
+// reported in resp, skipping those the backend left empty.
+func applyModelVersion(resp *model.LLMResponse, modelVersion, systemFingerprint string) {
+	if modelVersion != "" {
+		setCustomMetadata(resp, ModelVersionMetadataKey, modelVersion)
+	}
+	if systemFingerprint != "" {
+		setCustomMetadata(resp, SystemFingerprintMetadataKey, systemFingerprint)
+	}
+}
+
+// convertUsage converts OpenAI token usage into genai usage metadata. Hidden
+// reasoning tokens, which OpenAI counts as completion tokens, are also
+// reported as ThoughtsTokenCount.
+func convertUsage(usage openai.Usage) *genai.GenerateContentResponseUsageMetadata {
+	metadata := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     int32(usage.PromptTokens),
+		CandidatesTokenCount: int32(usage.CompletionTokens),
+		TotalTokenCount:      int32(usage.TotalTokens),
+	}
+	if usage.PromptTokensDetails != nil {
+		metadata.CachedContentTokenCount = int32(usage.PromptTokensDetails.CachedTokens)
+	}
+	if usage.CompletionTokensDetails != nil {
+		metadata.ThoughtsTokenCount = int32(usage.CompletionTokensDetails.ReasoningTokens)
+	}
+	return metadata
+}
+
+// convertChatCompletionChoice converts the message of a single choice into
+// genai content.
+func convertChatCompletionChoice(choice openai.ChatCompletionChoice) *genai.Content {
 	content := &genai.Content{
 		Role:  genai.RoleModel,
 		Parts: []*genai.Part{},
 	}
 
+	// Convert reasoning content, returned by DeepSeek-style endpoints
+	if choice.Message.ReasoningContent != "" {
+		content.Parts = append(content.Parts, &genai.Part{Text: choice.Message.ReasoningContent, Thought: true})
+	}
+
 	// Convert message content
 	if choice.Message.Content != "" {
 		content.Parts = append(content.Parts, &genai.Part{Text: choice.Message.Content})
@@ -461,46 +1625,27 @@ func convertChatCompletionResponse(resp *openai.ChatCompletionResponse) (*model.
 		}
 	}
 
-	// Convert usage metadata
-	var usageMetadata *genai.GenerateContentResponseUsageMetadata
-	if resp.Usage.TotalTokens > 0 {
-		usageMetadata = &genai.GenerateContentResponseUsageMetadata{
-			PromptTokenCount:     int32(resp.Usage.PromptTokens),
-			CandidatesTokenCount: int32(resp.Usage.CompletionTokens),
-			TotalTokenCount:      int32(resp.Usage.TotalTokens),
-		}
-		if resp.Usage.PromptTokensDetails != nil {
-			usageMetadata.CachedContentTokenCount = int32(resp.Usage.PromptTokensDetails.CachedTokens)
-		}
-	}
-
-	return &model.LLMResponse{
-		Content:       content,
-		UsageMetadata: usageMetadata,
-		FinishReason:  convertFinishReason(string(choice.FinishReason)),
-		TurnComplete:  true,
-	}, nil
+	return content
 }
 
-func convertTools(genaiTools []*genai.Tool) ([]openai.Tool, error) {
+func convertTools(genaiTools []*genai.Tool, opts schemaOptions) ([]openai.Tool, error) {
 	var openaiTools []openai.Tool
+	var unsupported []string
 
-	for _, genaiTool := range genaiTools {
+	for i, genaiTool := range genaiTools {
 		if genaiTool == nil {
 			continue
 		}
-
-		// TODO
-		if genaiTool.GoogleSearch != nil ||
-			genaiTool.CodeExecution != nil ||
-			genaiTool.FileSearch != nil ||
-			genaiTool.Retrieval != nil ||
-			genaiTool.ComputerUse != nil {
-			return nil, fmt.Errorf("GoogleSearch is not supported")
-		}
+		unsupported = append(unsupported, builtinTools(genaiTool)...)
 
 		// Convert function declarations
-		for _, funcDecl := range genaiTool.FunctionDeclarations {
+		for j, funcDecl := range genaiTool.FunctionDeclarations {
+			if funcDecl == nil {
+				if opts.lenientTools {
+					continue
+				}
+				return nil, fmt.Errorf("function declaration %d of tool %d is nil", j, i)
+			}
 			openaiTool := openai.Tool{
 				Type: openai.ToolTypeFunction,
 				Function: &openai.FunctionDefinition{
@@ -509,92 +1654,36 @@ func convertTools(genaiTools []*genai.Tool) ([]openai.Tool, error) {
 					Parameters:  funcDecl.ParametersJsonSchema,
 				},
 			}
-			if openaiTool.Function.Parameters == nil {
-				openaiTool.Function.Parameters = funcDecl.Parameters
+			if openaiTool.Function.Parameters == nil && funcDecl.Parameters != nil {
+				params, err := convertSchema(funcDecl.Parameters, opts)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert parameters for tool %s: %w", funcDecl.Name, err)
+				}
+				openaiTool.Function.Parameters = params
 			}
 			if openaiTool.Function.Parameters == nil {
 				return nil, fmt.Errorf("funcDecl.Parameters is nil for tool %s", funcDecl.Name)
 			}
-
-			openaiTools = append(openaiTools, openaiTool)
-		}
-	}
-
-	return openaiTools, nil
-}
-
-func convertSchema(schema *genai.Schema) (map[string]any, error) {
-	if schema == nil {
-		return map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
-		}, nil
-	}
-
-	result := make(map[string]any)
-
-	// Convert type
-	if schema.Type != genai.TypeUnspecified {
-		result["type"] = convertSchemaType(schema.Type)
-	}
-
-	// Add description
-	if schema.Description != "" {
-		result["description"] = schema.Description
-	}
-
-	// Convert properties recursively
-	if len(schema.Properties) > 0 {
-		properties := make(map[string]any)
-		for propName, propSchema := range schema.Properties {
-			convertedProp, err := convertSchema(propSchema)
-			if err != nil {
-				return nil, err
+			if opts.strict {
+				params, err := toJSONSchemaMap(openaiTool.Function.Parameters)
+				if err == nil {
+					err = strictJSONSchema(params, opts.nullable)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("tool %s is incompatible with strict mode: %w", funcDecl.Name, err)
+				}
+				openaiTool.Function.Parameters = params
+				openaiTool.Function.Strict = true
 			}
-			properties[propName] = convertedProp
-		}
-		result["properties"] = properties
-	}
 
-	// Add required fields
-	if len(schema.Required) > 0 {
-		result["required"] = schema.Required
-	}
-
-	// Convert array items
-	if schema.Items != nil {
-		items, err := convertSchema(schema.Items)
-		if err != nil {
-			return nil, err
+			openaiTools = append(openaiTools, openaiTool)
 		}
-		result["items"] = items
 	}
 
-	// Add enum if present
-	if len(schema.Enum) > 0 {
-		result["enum"] = schema.Enum
-	}
-
-	return result, nil
-}
-
-func convertSchemaType(t genai.Type) string {
-	switch t {
-	case genai.TypeString:
-		return "string"
-	case genai.TypeNumber:
-		return "number"
-	case genai.TypeInteger:
-		return "integer"
-	case genai.TypeBoolean:
-		return "boolean"
-	case genai.TypeArray:
-		return "array"
-	case genai.TypeObject:
-		return "object"
-	default:
-		return "string"
+	if len(unsupported) > 0 && !opts.lenientTools {
+		return nil, &UnsupportedToolsError{Tools: unsupported}
 	}
+	return openaiTools, nil
 }
 
 func convertRoleToOpenAI(role string) string {
@@ -605,54 +1694,109 @@ func convertRoleToOpenAI(role string) string {
 		return openai.ChatMessageRoleAssistant
 	case "system":
 		return openai.ChatMessageRoleSystem
+	case "function", "tool":
+		return openai.ChatMessageRoleTool
 	default:
 		return openai.ChatMessageRoleUser
 	}
 }
 
+// convertRoleFromOpenAI maps an OpenAI message role onto the genai role used
+// for the content converted from it. Unknown roles are taken to be the model.
+func convertRoleFromOpenAI(role string) string {
+	switch role {
+	case openai.ChatMessageRoleUser:
+		return genai.RoleUser
+	case openai.ChatMessageRoleSystem:
+		return "system"
+	case openai.ChatMessageRoleTool, openai.ChatMessageRoleFunction:
+		return "tool"
+	default:
+		return genai.RoleModel
+	}
+}
+
+// applyToolCallsFinishReason records under FinishReasonMetadataKey that resp
+// ended in tool calls, which its FinishReason cannot express.
+func applyToolCallsFinishReason(resp *model.LLMResponse, reason openai.FinishReason) {
+	if reason == openai.FinishReasonToolCalls || reason == openai.FinishReasonFunctionCall {
+		setCustomMetadata(resp, FinishReasonMetadataKey, string(reason))
+	}
+}
+
+// convertFinishReason maps an OpenAI finish reason onto genai. genai has no
+// reason for a turn that ends in tool calls; like Gemini, such turns finish
+// with FinishReasonStop and are told apart by their FunctionCall parts or the
+// raw reason stored under FinishReasonMetadataKey.
+//
+// Common synonyms sent by OpenAI-compatible backends, such as Anthropic's
+// end_turn and max_tokens or the eos of local inference servers, are
+// recognized too, regardless of case.
 func convertFinishReason(reason string) genai.FinishReason {
-	switch reason {
-	case "stop":
+	switch strings.ToLower(reason) {
+	case "stop", "end_turn", "stop_sequence", "eos", "eos_token":
 		return genai.FinishReasonStop
-	case "length":
+	case "length", "max_tokens", "max_output_tokens", "model_length":
 		return genai.FinishReasonMaxTokens
-	case "tool_calls", "function_call":
+	case "tool_calls", "function_call", "tool_use":
 		return genai.FinishReasonStop
-	case "content_filter":
+	case "content_filter", "safety":
 		return genai.FinishReasonSafety
 	default:
 		return genai.FinishReasonUnspecified
 	}
 }
 
-func extractTextFromContent(content *genai.Content) string {
+// finishReason maps a backend finish reason onto genai, preferring the
+// FinishReasons override.
+func (o *OpenAIModel) finishReason(reason string) genai.FinishReason {
+	if mapped, ok := o.FinishReasons[reason]; ok {
+		return mapped
+	}
+	return convertFinishReason(reason)
+}
+
+// defaultTextSeparator joins text parts when no TextSeparator is configured.
+const defaultTextSeparator = "\n"
+
+func (o *OpenAIModel) textSeparator() string {
+	if o.TextSeparator != nil {
+		return *o.TextSeparator
+	}
+	return defaultTextSeparator
+}
+
+// extractTextFromContent returns the text parts of content joined by sep.
+func extractTextFromContent(content *genai.Content, sep string) string {
 	if content == nil {
 		return ""
 	}
 	var texts []string
 	for _, part := range content.Parts {
-		if part.Text != "" {
+		if hasText(part) {
 			texts = append(texts, part.Text)
 		}
 	}
-	return joinTexts(texts)
+	return strings.Join(texts, sep)
 }
 
-func joinTexts(texts []string) string {
-	if len(texts) == 0 {
-		return ""
-	}
-	if len(texts) == 1 {
-		return texts[0]
-	}
-	result := ""
-	for i, text := range texts {
-		if i > 0 {
-			result += "\n"
-		}
-		result += text
+// hasText reports whether part carries non-blank text. Whitespace-only text
+// parts are dropped during conversion, while whitespace around other text is
+// preserved as is.
+func hasText(part *genai.Part) bool {
+	return strings.TrimSpace(part.Text) != ""
+}
+
+// marshalJSON is json.Marshal without HTML escaping, so that tool arguments
+// and results containing &, < or > reach the model as written.
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
 	}
-	return result
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
 }
 
 func parseJSONArgs(argsJSON string) map[string]any {
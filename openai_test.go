@@ -1,8 +1,14 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -33,6 +39,16 @@ func TestConvertRoleToOpenAI(t *testing.T) {
 			role: "system",
 			want: openai.ChatMessageRoleSystem,
 		},
+		{
+			name: "legacy function role",
+			role: "function",
+			want: openai.ChatMessageRoleTool,
+		},
+		{
+			name: "tool role",
+			role: "tool",
+			want: openai.ChatMessageRoleTool,
+		},
 		{
 			name: "unknown role defaults to user",
 			role: "unknown",
@@ -76,6 +92,31 @@ func TestConvertFinishReason(t *testing.T) {
 			reason: "content_filter",
 			want:   genai.FinishReasonSafety,
 		},
+		{
+			name:   "end_turn",
+			reason: "end_turn",
+			want:   genai.FinishReasonStop,
+		},
+		{
+			name:   "eos",
+			reason: "eos",
+			want:   genai.FinishReasonStop,
+		},
+		{
+			name:   "max_tokens",
+			reason: "max_tokens",
+			want:   genai.FinishReasonMaxTokens,
+		},
+		{
+			name:   "upper case MAX_TOKENS",
+			reason: "MAX_TOKENS",
+			want:   genai.FinishReasonMaxTokens,
+		},
+		{
+			name:   "safety",
+			reason: "safety",
+			want:   genai.FinishReasonSafety,
+		},
 		{
 			name:   "unknown",
 			reason: "unknown",
@@ -93,6 +134,65 @@ func TestConvertFinishReason(t *testing.T) {
 	}
 }
 
+func TestGenerateContent_FinishReasonsOverride(t *testing.T) {
+	s, m := newFakeServer(t)
+	m.FinishReasons = map[string]genai.FinishReason{
+		"recitation": genai.FinishReasonRecitation,
+		"stop":       genai.FinishReasonOther,
+	}
+	s.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{
+		{Index: 0, Message: openai.ChatCompletionMessage{Role: "assistant", Content: "a"}, FinishReason: "recitation"},
+		{Index: 1, Message: openai.ChatCompletionMessage{Role: "assistant", Content: "b"}, FinishReason: "end_turn"},
+	}}
+	s.chunks = []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "a"}, "stop"),
+	}
+
+	for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if resp.FinishReason != genai.FinishReasonRecitation {
+			t.Errorf("FinishReason = %q, want %q", resp.FinishReason, genai.FinishReasonRecitation)
+		}
+		candidates, _ := resp.CustomMetadata[CandidatesMetadataKey].([]*genai.Candidate)
+		if len(candidates) != 2 || candidates[1].FinishReason != genai.FinishReasonStop {
+			t.Errorf("candidates = %+v, want the second finishing with %q", candidates, genai.FinishReasonStop)
+		}
+	}
+
+	for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if !resp.Partial && resp.FinishReason != genai.FinishReasonOther {
+			t.Errorf("streamed FinishReason = %q, want %q", resp.FinishReason, genai.FinishReasonOther)
+		}
+	}
+}
+
+func TestGenerateContent_FinishReasonsKeepRefusal(t *testing.T) {
+	s, m := newFakeServer(t)
+	m.FinishReasons = map[string]genai.FinishReason{"stop": genai.FinishReasonOther}
+	s.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{
+		{Message: openai.ChatCompletionMessage{Role: "assistant", Refusal: "I can't help with that."}, FinishReason: "stop"},
+	}}
+	s.chunks = []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Refusal: "I can't help with that."}, "stop"),
+	}
+
+	for _, stream := range []bool{false, true} {
+		for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), stream) {
+			if err != nil {
+				t.Fatalf("GenerateContent(stream=%v) error = %v", stream, err)
+			}
+			if !resp.Partial && resp.FinishReason != genai.FinishReasonSafety {
+				t.Errorf("GenerateContent(stream=%v) FinishReason = %q, want %q", stream, resp.FinishReason, genai.FinishReasonSafety)
+			}
+		}
+	}
+}
+
 func TestToOpenAIChatCompletionMessage(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -201,11 +301,15 @@ func TestToOpenAIChatCompletionMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := toOpenAIChatCompletionMessage(tt.content)
+			msgs, err := (&OpenAIModel{}).toOpenAIChatCompletionMessage(tt.content)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("toOpenAIChatCompletionMessage() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if len(msgs) != 1 {
+				t.Fatalf("toOpenAIChatCompletionMessage() returned %d messages, want 1", len(msgs))
+			}
+			got := msgs[0]
 
 			// For function call messages, we need to compare the arguments as JSON
 			if len(tt.want.ToolCalls) > 0 && len(got.ToolCalls) > 0 {
@@ -250,6 +354,205 @@ func TestToOpenAIChatCompletionMessage(t *testing.T) {
 	}
 }
 
+func TestToOpenAIChatCompletionMessage_WhitespaceText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *genai.Content
+		want    openai.ChatCompletionMessage
+	}{
+		{
+			name: "single whitespace-only part has no content",
+			content: &genai.Content{
+				Role:  "model",
+				Parts: []*genai.Part{{Text: " \n"}},
+			},
+			want: openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleAssistant,
+			},
+		},
+		{
+			name: "surrounding whitespace is preserved",
+			content: &genai.Content{
+				Role:  "user",
+				Parts: []*genai.Part{{Text: "  Hello\n"}},
+			},
+			want: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: "  Hello\n",
+			},
+		},
+		{
+			name: "leading whitespace-only part is dropped",
+			content: &genai.Content{
+				Role:  "user",
+				Parts: []*genai.Part{{Text: " "}, {Text: "Hello"}},
+			},
+			want: openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleUser,
+				MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "Hello"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := (&OpenAIModel{}).toOpenAIChatCompletionMessage(tt.content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("toOpenAIChatCompletionMessage() returned %d messages, want 1", len(msgs))
+			}
+			if diff := cmp.Diff(tt.want, msgs[0]); diff != "" {
+				t.Errorf("toOpenAIChatCompletionMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_PromoteDataURIImages(t *testing.T) {
+	const uri = "data:image/png;base64,iVBORw0KGgo="
+	content := &genai.Content{
+		Role:  "user",
+		Parts: []*genai.Part{{Text: "Describe " + uri + " please"}},
+	}
+
+	tests := []struct {
+		name string
+		m    *OpenAIModel
+		want openai.ChatCompletionMessage
+	}{
+		{
+			name: "disabled",
+			m:    &OpenAIModel{},
+			want: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: "Describe " + uri + " please",
+			},
+		},
+		{
+			name: "enabled",
+			m:    &OpenAIModel{PromoteDataURIImages: true},
+			want: openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleUser,
+				MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "Describe "},
+					{
+						Type: openai.ChatMessagePartTypeImageURL,
+						ImageURL: &openai.ChatMessageImageURL{
+							URL:    uri,
+							Detail: openai.ImageURLDetailAuto,
+						},
+					},
+					{Type: openai.ChatMessagePartTypeText, Text: " please"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := tt.m.toOpenAIChatCompletionMessage(content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("toOpenAIChatCompletionMessage() returned %d messages, want 1", len(msgs))
+			}
+			if diff := cmp.Diff(tt.want, msgs[0]); diff != "" {
+				t.Errorf("toOpenAIChatCompletionMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_FunctionResponseProfile(t *testing.T) {
+	content := &genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{
+			FunctionResponse: &genai.FunctionResponse{
+				ID:       "call_123",
+				Name:     "get_weather",
+				Response: map[string]any{"condition": "sunny"},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		profile Profile
+		want    openai.ChatCompletionMessage
+	}{
+		{
+			name:    "openai",
+			profile: ProfileOpenAI,
+			want: openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    `{"condition":"sunny"}`,
+				ToolCallID: "call_123",
+			},
+		},
+		{
+			name:    "legacy",
+			profile: ProfileLegacy,
+			want: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleFunction,
+				Content: `{"condition":"sunny"}`,
+				Name:    "get_weather",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{Profile: tt.profile}
+			msgs, err := m.toOpenAIChatCompletionMessage(content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if diff := cmp.Diff([]openai.ChatCompletionMessage{tt.want}, msgs); diff != "" {
+				t.Errorf("toOpenAIChatCompletionMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_FunctionResponseContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]any
+		raw      bool
+		want     string
+	}{
+		{name: "map", response: map[string]any{"condition": "sunny", "temp": 21}, want: `{"condition":"sunny","temp":21}`},
+		{name: "string result", response: map[string]any{"result": "It is sunny."}, want: "It is sunny."},
+		{name: "array result", response: map[string]any{"result": []any{"a", "b"}}, want: `["a","b"]`},
+		{name: "number result", response: map[string]any{"result": 42}, want: "42"},
+		{name: "result among other keys", response: map[string]any{"result": "ok", "code": 0}, want: `{"code":0,"result":"ok"}`},
+		{name: "raw", response: map[string]any{"result": "It is sunny."}, raw: true, want: `{"result":"It is sunny."}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{RawFunctionResponses: tt.raw}
+			msgs, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+				Role: "user",
+				Parts: []*genai.Part{{
+					FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "lookup", Response: tt.response},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if got := msgs[0].Content; got != tt.want {
+				t.Errorf("Content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestConvertChatCompletionResponse(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -338,6 +641,34 @@ func TestConvertChatCompletionResponse(t *testing.T) {
 					CandidatesTokenCount: 20,
 					TotalTokenCount:      35,
 				},
+				FinishReason:   genai.FinishReasonStop,
+				CustomMetadata: map[string]any{FinishReasonMetadataKey: "tool_calls"},
+				TurnComplete:   true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "response with reasoning content",
+			resp: &openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:             openai.ChatMessageRoleAssistant,
+							ReasoningContent: "The user asks for 2+2, which is 4.",
+							Content:          "4",
+						},
+						FinishReason: "stop",
+					},
+				},
+			},
+			want: &model.LLMResponse{
+				Content: &genai.Content{
+					Role: "model",
+					Parts: []*genai.Part{
+						{Text: "The user asks for 2+2, which is 4.", Thought: true},
+						{Text: "4"},
+					},
+				},
 				FinishReason: genai.FinishReasonStop,
 				TurnComplete: true,
 			},
@@ -355,7 +686,7 @@ func TestConvertChatCompletionResponse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := convertChatCompletionResponse(tt.resp)
+			got, err := convertChatCompletionResponse(tt.resp, convertFinishReason)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("convertChatCompletionResponse() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -542,7 +873,8 @@ func TestToOpenAIChatCompletionRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := toOpenAIChatCompletionRequest(tt.req, tt.modelName)
+			m := &OpenAIModel{ModelName: tt.modelName}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), tt.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("toOpenAIChatCompletionRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -606,71 +938,406 @@ func TestToOpenAIChatCompletionRequest(t *testing.T) {
 	}
 }
 
-func TestConvertTools(t *testing.T) {
+func TestToOpenAIChatCompletionRequest_MultimodalSystemInstruction(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Which logo is this?"}}}},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: &genai.Content{Parts: []*genai.Part{
+				{Text: "Compare images against this reference logo."},
+				{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte("logo")}},
+			}},
+		},
+	}
+
 	tests := []struct {
-		name      string
-		genaiTool []*genai.Tool
-		want      []openai.Tool
-		wantErr   bool
+		name string
+		m    *OpenAIModel
+		want openai.ChatCompletionMessage
 	}{
 		{
-			name: "simple function declaration",
-			genaiTool: []*genai.Tool{
-				{
-					FunctionDeclarations: []*genai.FunctionDeclaration{
-						{
-							Name:        "get_weather",
-							Description: "Get the current weather",
-							Parameters: &genai.Schema{
-								Type: genai.TypeObject,
-								Properties: map[string]*genai.Schema{
-									"location": {
-										Type:        genai.TypeString,
-										Description: "The city and state",
-									},
-									"unit": {
-										Type: genai.TypeString,
-										Enum: []string{"celsius", "fahrenheit"},
-									},
-								},
-								Required: []string{"location"},
-							},
-						},
-					},
-				},
+			name: "text fallback",
+			m:    &OpenAIModel{ModelName: "gpt-4o"},
+			want: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: "Compare images against this reference logo.",
 			},
-			want: []openai.Tool{
-				{
-					Type: openai.ToolTypeFunction,
-					Function: &openai.FunctionDefinition{
-						Name:        "get_weather",
-						Description: "Get the current weather",
-						Parameters: map[string]any{
-							"type": "object",
-							"properties": map[string]any{
-								"location": map[string]any{
-									"type":        "string",
-									"description": "The city and state",
-								},
-								"unit": map[string]any{
-									"type": "string",
-									"enum": []string{"celsius", "fahrenheit"},
-								},
-							},
-							"required": []string{"location"},
-						},
-					},
+		},
+		{
+			name: "multimodal",
+			m:    &OpenAIModel{ModelName: "gpt-4o", MultimodalSystemInstruction: true},
+			want: openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleSystem,
+				MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "Compare images against this reference logo."},
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+						URL:    "data:image/png;base64,bG9nbw==",
+						Detail: openai.ImageURLDetailAuto,
+					}},
 				},
 			},
-			wantErr: false,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if len(got.Messages) != 2 {
+				t.Fatalf("got %d messages, want 2", len(got.Messages))
+			}
+			if diff := cmp.Diff(tt.want, got.Messages[0]); diff != "" {
+				t.Errorf("system message mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_LeadingSystemContent(t *testing.T) {
+	instruction := &genai.Content{Parts: []*genai.Part{{Text: "Be concise."}}}
+	systemTurn := &genai.Content{Role: "system", Parts: []*genai.Part{{Text: "Answer in French."}}}
+	userTurn := &genai.Content{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}
+
+	tests := []struct {
+		name        string
+		instruction *genai.Content
+		contents    []*genai.Content
+		want        []openai.ChatCompletionMessage
+	}{
 		{
-			name: "multiple function declarations",
-			genaiTool: []*genai.Tool{
-				{
-					FunctionDeclarations: []*genai.FunctionDeclaration{
-						{
-							Name:        "add",
+			name:        "instruction only",
+			instruction: instruction,
+			contents:    []*genai.Content{userTurn},
+			want: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "Be concise."},
+				{Role: openai.ChatMessageRoleUser, Content: "Hello"},
+			},
+		},
+		{
+			name:     "leading system content only",
+			contents: []*genai.Content{systemTurn, userTurn},
+			want: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "Answer in French."},
+				{Role: openai.ChatMessageRoleUser, Content: "Hello"},
+			},
+		},
+		{
+			name:        "both present",
+			instruction: instruction,
+			contents:    []*genai.Content{systemTurn, userTurn},
+			want: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "Be concise.\n\nAnswer in French."},
+				{Role: openai.ChatMessageRoleUser, Content: "Hello"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o"}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: tt.contents,
+				Config:   &genai.GenerateContentConfig{SystemInstruction: tt.instruction},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Messages); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_DropEmptyAssistantMessages(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}},
+			{Role: "model", Parts: []*genai.Part{{Text: ""}}},
+			{Role: "user", Parts: []*genai.Part{{Text: "Are you there?"}}},
+		},
+		Config: &genai.GenerateContentConfig{},
+	}
+
+	tests := []struct {
+		name     string
+		drop     bool
+		wantRole []string
+	}{
+		{
+			name: "kept by default",
+			drop: false,
+			wantRole: []string{
+				openai.ChatMessageRoleUser,
+				openai.ChatMessageRoleAssistant,
+				openai.ChatMessageRoleUser,
+			},
+		},
+		{
+			name: "dropped when enabled",
+			drop: true,
+			wantRole: []string{
+				openai.ChatMessageRoleUser,
+				openai.ChatMessageRoleUser,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4", DropEmptyAssistantMessages: tt.drop}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			var gotRole []string
+			for _, msg := range got.Messages {
+				gotRole = append(gotRole, msg.Role)
+			}
+			if diff := cmp.Diff(tt.wantRole, gotRole); diff != "" {
+				t.Errorf("message roles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_ReorderToolResponses(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Weather in Paris and Rome?"}}},
+			{Role: "user", Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call_rome", Name: "weather", Response: map[string]any{"sky": "rainy"}}},
+			}},
+			{Role: "model", Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{ID: "call_paris", Name: "weather", Args: map[string]any{"city": "Paris"}}},
+				{FunctionCall: &genai.FunctionCall{ID: "call_rome", Name: "weather", Args: map[string]any{"city": "Rome"}}},
+			}},
+			{Role: "user", Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call_paris", Name: "weather", Response: map[string]any{"sky": "sunny"}}},
+			}},
+			{Role: "model", Parts: []*genai.Part{{Text: "Sunny in Paris, rainy in Rome."}}},
+		},
+		Config: &genai.GenerateContentConfig{},
+	}
+
+	m := &OpenAIModel{ModelName: "gpt-4o"}
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+
+	type turn struct{ Role, ToolCallID string }
+	var gotTurns []turn
+	for _, msg := range got.Messages {
+		gotTurns = append(gotTurns, turn{msg.Role, msg.ToolCallID})
+	}
+	wantTurns := []turn{
+		{openai.ChatMessageRoleUser, ""},
+		{openai.ChatMessageRoleAssistant, ""},
+		{openai.ChatMessageRoleTool, "call_paris"},
+		{openai.ChatMessageRoleTool, "call_rome"},
+		{openai.ChatMessageRoleAssistant, ""},
+	}
+	if diff := cmp.Diff(wantTurns, gotTurns); diff != "" {
+		t.Errorf("message sequence mismatch (-want +got):\n%s", diff)
+	}
+	if err := validateRequest(got); err != nil {
+		t.Errorf("validateRequest() error = %v", err)
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_LogitBias(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}
+
+	tests := []struct {
+		name    string
+		bias    map[string]int
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "valid", bias: map[string]int{"1639": -100, "50256": 100, "42": 5}},
+		{name: "below range", bias: map[string]int{"1639": -101}, wantErr: true},
+		{name: "above range", bias: map[string]int{"1639": 250}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", LogitBias: tt.bias}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.bias, got.LogitBias); diff != "" {
+				t.Errorf("LogitBias mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_StoreMetadata(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}
+	tooMany := map[string]string{}
+	for i := 0; i <= maxMetadataPairs; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name     string
+		store    bool
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{name: "empty metadata", store: true, metadata: map[string]string{}},
+		{name: "populated", store: true, metadata: map[string]string{"team": "search", "eval": "v2"}},
+		{name: "too many pairs", metadata: tooMany, wantErr: true},
+		{name: "key too long", metadata: map[string]string{strings.Repeat("k", 65): "v"}, wantErr: true},
+		{name: "value too long", metadata: map[string]string{"k": strings.Repeat("v", 513)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", Store: tt.store, Metadata: tt.metadata}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Store != tt.store {
+				t.Errorf("Store = %v, want %v", got.Store, tt.store)
+			}
+			if len(tt.metadata) == 0 {
+				if got.Metadata != nil {
+					t.Errorf("Metadata = %v, want nil", got.Metadata)
+				}
+			} else if diff := cmp.Diff(tt.metadata, got.Metadata); diff != "" {
+				t.Errorf("Metadata mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_ServiceTier(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}
+
+	tests := []struct {
+		name    string
+		tier    openai.ServiceTier
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "auto", tier: openai.ServiceTierAuto},
+		{name: "flex", tier: openai.ServiceTierFlex},
+		{name: "unknown", tier: "turbo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", ServiceTier: tt.tier}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.ServiceTier != tt.tier {
+				t.Errorf("ServiceTier = %q, want %q", got.ServiceTier, tt.tier)
+			}
+		})
+	}
+}
+
+func TestConvertChatCompletionResponse_ServiceTier(t *testing.T) {
+	got, err := convertChatCompletionResponse(&openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Hi"},
+			FinishReason: openai.FinishReasonStop,
+		}},
+		ServiceTier: openai.ServiceTierFlex,
+	}, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if tier := got.CustomMetadata[ServiceTierMetadataKey]; tier != "flex" {
+		t.Errorf("CustomMetadata[%q] = %v, want %q", ServiceTierMetadataKey, tier, "flex")
+	}
+}
+
+func TestConvertTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		genaiTool []*genai.Tool
+		want      []openai.Tool
+		wantErr   bool
+	}{
+		{
+			name: "simple function declaration",
+			genaiTool: []*genai.Tool{
+				{
+					FunctionDeclarations: []*genai.FunctionDeclaration{
+						{
+							Name:        "get_weather",
+							Description: "Get the current weather",
+							Parameters: &genai.Schema{
+								Type: genai.TypeObject,
+								Properties: map[string]*genai.Schema{
+									"location": {
+										Type:        genai.TypeString,
+										Description: "The city and state",
+									},
+									"unit": {
+										Type: genai.TypeString,
+										Enum: []string{"celsius", "fahrenheit"},
+									},
+								},
+								Required: []string{"location"},
+							},
+						},
+					},
+				},
+			},
+			want: []openai.Tool{
+				{
+					Type: openai.ToolTypeFunction,
+					Function: &openai.FunctionDefinition{
+						Name:        "get_weather",
+						Description: "Get the current weather",
+						Parameters: map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"location": map[string]any{
+									"type":        "string",
+									"description": "The city and state",
+								},
+								"unit": map[string]any{
+									"type": "string",
+									"enum": []string{"celsius", "fahrenheit"},
+								},
+							},
+							"required": []string{"location"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "multiple function declarations",
+			genaiTool: []*genai.Tool{
+				{
+					FunctionDeclarations: []*genai.FunctionDeclaration{
+						{
+							Name:        "add",
 							Description: "Add two numbers",
 							Parameters: &genai.Schema{
 								Type: genai.TypeObject,
@@ -746,7 +1413,7 @@ func TestConvertTools(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := convertTools(tt.genaiTool)
+			got, err := convertTools(tt.genaiTool, schemaOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("convertTools() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -806,6 +1473,17 @@ func TestExtractTextFromContent(t *testing.T) {
 			},
 			want: "Hello\nWorld",
 		},
+		{
+			name: "whitespace-only parts are skipped",
+			content: &genai.Content{
+				Parts: []*genai.Part{
+					{Text: " "},
+					{Text: " Hello "},
+					{Text: "\n\t"},
+				},
+			},
+			want: " Hello ",
+		},
 		{
 			name: "mixed parts with non-text",
 			content: &genai.Content{
@@ -821,7 +1499,7 @@ func TestExtractTextFromContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractTextFromContent(tt.content)
+			got := extractTextFromContent(tt.content, defaultTextSeparator)
 			if got != tt.want {
 				t.Errorf("extractTextFromContent() = %q, want %q", got, tt.want)
 			}
@@ -829,6 +1507,68 @@ func TestExtractTextFromContent(t *testing.T) {
 	}
 }
 
+func TestToOpenAIChatCompletionRequest_TextSeparator(t *testing.T) {
+	instruction := &genai.Content{Parts: []*genai.Part{{Text: "Be brief."}, {Text: "Answer in French."}}}
+	tests := []struct {
+		name string
+		sep  *string
+		want string
+	}{
+		{name: "default", want: "Be brief.\nAnswer in French."},
+		{name: "custom", sep: genai.Ptr(" "), want: "Be brief. Answer in French."},
+		{name: "empty", sep: genai.Ptr(""), want: "Be brief.Answer in French."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", TextSeparator: tt.sep}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hi"}}}},
+				Config:   &genai.GenerateContentConfig{SystemInstruction: instruction},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.Messages[0].Content != tt.want {
+				t.Errorf("system message = %q, want %q", got.Messages[0].Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_StructuredToolContent(t *testing.T) {
+	content := &genai.Content{Role: "user", Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+		ID:       "call_1",
+		Name:     "get_weather",
+		Response: map[string]any{"result": "sunny"},
+	}}}}
+	tests := []struct {
+		name       string
+		structured bool
+		want       string
+	}{
+		{name: "string", want: `{"role":"tool","content":"sunny","tool_call_id":"call_1"}`},
+		{name: "array", structured: true, want: `{"role":"tool","content":[{"type":"text","text":"sunny"}],"tool_call_id":"call_1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{StructuredToolContent: tt.structured}
+			msgs, err := m.toOpenAIChatCompletionMessage(content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			data, err := json.Marshal(msgs)
+			if err != nil {
+				t.Fatalf("failed to marshal messages: %v", err)
+			}
+			if got := string(data); got != "["+tt.want+"]" {
+				t.Errorf("messages = %s, want [%s]", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseJSONArgs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -871,9 +1611,81 @@ func TestOpenAIModel_Name(t *testing.T) {
 	}
 }
 
-// TestOpenAIModel_GenerateContent would require mocking the OpenAI client
-// which is complex. In practice, this would be tested with integration tests
-// or by using a mock server.
+func TestNewCheckedOpenAIModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelName string
+		apiKey    string
+		baseURL   string
+		wantErr   error
+	}{
+		{name: "valid", modelName: "gpt-4o", apiKey: "key"},
+		{name: "empty name", modelName: "", apiKey: "key", wantErr: ErrEmptyModelName},
+		{name: "blank name", modelName: "  ", apiKey: "key", wantErr: ErrEmptyModelName},
+		{name: "empty key without base URL", modelName: "gpt-4o", wantErr: ErrMissingAPIKey},
+		{name: "empty key with base URL", modelName: "llama3", baseURL: "http://localhost:11434/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCheckedOpenAIModel(tt.modelName, tt.apiKey, tt.baseURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewCheckedOpenAIModel() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Name() != tt.modelName {
+				t.Errorf("Name() = %q, want %q", got.Name(), tt.modelName)
+			}
+		})
+	}
+}
+
+func TestOpenAIModel_Client(t *testing.T) {
+	s, m := newFakeServer(t)
+	s.handle("/models", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ModelsList{Models: []openai.Model{{ID: "gpt-4o"}}})
+	})
+	if m.Client == nil {
+		t.Fatal("NewOpenAIModel() left Client nil")
+	}
+	var asLLM model.LLM = m
+	accessor, ok := asLLM.(interface{ OpenAIClient() *openai.Client })
+	if !ok {
+		t.Fatal("OpenAIModel has no OpenAIClient accessor")
+	}
+	if accessor.OpenAIClient() != m.Client {
+		t.Error("OpenAIClient() is not the client completions are sent through")
+	}
+
+	models, err := accessor.OpenAIClient().ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models.Models) != 1 || models.Models[0].ID != "gpt-4o" {
+		t.Errorf("ListModels() = %+v, want gpt-4o", models.Models)
+	}
+	// A model built as a literal around the same client sends completions
+	// through it too.
+	for _, llm := range []*OpenAIModel{m, {Client: m.Client, ModelName: "gpt-4o"}} {
+		for _, err := range llm.GenerateContent(context.Background(), &model.LLMRequest{
+			Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		}, false) {
+			if err != nil {
+				t.Fatalf("GenerateContent() error = %v", err)
+			}
+		}
+	}
+	var paths []string
+	for _, req := range s.requestsTo("") {
+		paths = append(paths, req.url.Path)
+	}
+	if diff := cmp.Diff([]string{"/models", "/chat/completions", "/chat/completions"}, paths); diff != "" {
+		t.Errorf("request paths mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// GenerateContent itself is exercised end to end against chatServer in
+// harness_test.go.
 func TestOpenAIModel_GenerateContent_Interface(t *testing.T) {
 	// Verify that OpenAIModel implements model.LLM interface
 	var _ model.LLM = &OpenAIModel{}
@@ -890,21 +1702,609 @@ func TestOpenAIModel_GenerateContent_Interface(t *testing.T) {
 	}
 }
 
-// Benchmark tests
-func TestToolCallBuilder(t *testing.T) {
-	// Simulate the streaming scenario where tool call comes in multiple chunks
-	builder := &toolCallBuilder{
-		id:   "call_123",
-		name: "get_weather",
-		args: "",
-	}
+// fakeStream replays a fixed sequence of chunks and then returns err, or
+// io.EOF when err is nil.
+type fakeStream struct {
+	chunks []openai.ChatCompletionStreamResponse
+	err    error
+	closed bool
+}
 
-	// First chunk: ID and name
-	builder.id = "call_123"
-	builder.name = "get_weather"
+func (s *fakeStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return openai.ChatCompletionStreamResponse{}, s.err
+		}
+		return openai.ChatCompletionStreamResponse{}, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
 
-	// Second chunk: partial arguments
-	builder.args += `{"location":`
+func (s *fakeStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+// deltaChunk returns a stream chunk carrying delta as its only choice.
+func deltaChunk(delta openai.ChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) openai.ChatCompletionStreamResponse {
+	return openai.ChatCompletionStreamResponse{
+		Choices: []openai.ChatCompletionStreamChoice{
+			{Delta: delta, FinishReason: finishReason},
+		},
+	}
+}
+
+// collectStream runs readStream over stream and returns every yielded response.
+func collectStream(t *testing.T, m *OpenAIModel, stream chatCompletionStream) []*model.LLMResponse {
+	t.Helper()
+	var resps []*model.LLMResponse
+	m.readStream(context.Background(), stream, func(resp *model.LLMResponse, err error) bool {
+		if err != nil {
+			t.Fatalf("readStream() error = %v", err)
+		}
+		resps = append(resps, resp)
+		return true
+	})
+	return resps
+}
+
+func TestReadStream_DeltaRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		lateRole string
+		wantRole string
+	}{
+		{name: "assistant", role: openai.ChatMessageRoleAssistant, wantRole: genai.RoleModel},
+		{name: "no role", wantRole: genai.RoleModel},
+		{name: "tool", role: openai.ChatMessageRoleTool, wantRole: "tool"},
+		{name: "later role change ignored", role: openai.ChatMessageRoleAssistant, lateRole: openai.ChatMessageRoleUser, wantRole: genai.RoleModel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := 0
+			stream := &fakeStream{
+				chunks: []openai.ChatCompletionStreamResponse{
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: tt.role, ReasoningContent: "Greet."}, ""),
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hi"}, ""),
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: tt.lateRole, Content: "!"}, ""),
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+						Index:    &index,
+						ID:       "call_1",
+						Function: openai.FunctionCall{Name: "wave", Arguments: "{}"},
+					}}}, openai.FinishReasonToolCalls),
+				},
+			}
+			resps := collectStream(t, &OpenAIModel{StreamToolCallDeltas: true}, stream)
+			// Partial text, thought and tool call responses share the role of
+			// the final one.
+			for i, resp := range resps {
+				if resp.Content.Role != tt.wantRole {
+					t.Errorf("response %d Content.Role = %q, want %q", i, resp.Content.Role, tt.wantRole)
+				}
+			}
+			final := resps[len(resps)-1]
+			if got := final.Content.Parts[1].Text; got != "Hi!" {
+				t.Errorf("text = %q, want %q", got, "Hi!")
+			}
+		})
+	}
+}
+
+func TestReadStream_ToolCallsWithoutIndex(t *testing.T) {
+	index := 0
+	stream := &fakeStream{
+		chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					ID:       "call_1",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+				}},
+			}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_2",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "get_time", Arguments: `{"zone":`},
+				}},
+			}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Function: openai.FunctionCall{Arguments: `"CET"}`},
+				}},
+			}, openai.FinishReasonToolCalls),
+		},
+	}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+	final := resps[len(resps)-1]
+
+	want := []*genai.Part{
+		{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"location": "Paris"}}},
+		{FunctionCall: &genai.FunctionCall{ID: "call_2", Name: "get_time", Args: map[string]any{"zone": "CET"}}},
+	}
+	if diff := cmp.Diff(want, final.Content.Parts, cmpopts.IgnoreUnexported(genai.Part{})); diff != "" {
+		t.Errorf("final parts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_ToolCallWithoutIndexBeforeIndexed(t *testing.T) {
+	index := 0
+	stream := &fakeStream{
+		chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_1",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "get_time", Arguments: `{"zone":"CET"}`},
+				}},
+			}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					ID:       "call_2",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+				}},
+			}, openai.FinishReasonToolCalls),
+		},
+	}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+	final := resps[len(resps)-1]
+
+	// The explicit index 0 must not land on the call that arrived without one
+	want := []*genai.Part{
+		{FunctionCall: &genai.FunctionCall{ID: "call_2", Name: "get_weather", Args: map[string]any{"location": "Paris"}}},
+		{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "get_time", Args: map[string]any{"zone": "CET"}}},
+	}
+	if diff := cmp.Diff(want, final.Content.Parts, cmpopts.IgnoreUnexported(genai.Part{})); diff != "" {
+		t.Errorf("final parts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_StreamToolCallDeltas(t *testing.T) {
+	index := 0
+	newStream := func() *fakeStream {
+		return &fakeStream{
+			chunks: []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{{
+						Index:    &index,
+						ID:       "call_1",
+						Type:     openai.ToolTypeFunction,
+						Function: openai.FunctionCall{Name: "get_weather"},
+					}},
+				}, ""),
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{{
+						Index:    &index,
+						Function: openai.FunctionCall{Arguments: `{"location":`},
+					}},
+				}, ""),
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{{
+						Index:    &index,
+						Function: openai.FunctionCall{Arguments: `"Paris"}`},
+					}},
+				}, openai.FinishReasonToolCalls),
+			},
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		resps := collectStream(t, &OpenAIModel{}, newStream())
+		if len(resps) != 1 {
+			t.Fatalf("got %d responses, want only the final one", len(resps))
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		resps := collectStream(t, &OpenAIModel{StreamToolCallDeltas: true}, newStream())
+		if len(resps) != 4 {
+			t.Fatalf("got %d responses, want 3 partial and 1 final", len(resps))
+		}
+
+		wantArgs := []map[string]any{{}, {}, {"location": "Paris"}}
+		for i, resp := range resps[:3] {
+			if !resp.Partial {
+				t.Errorf("response %d: Partial = false, want true", i)
+			}
+			fc := resp.Content.Parts[0].FunctionCall
+			if fc == nil {
+				t.Fatalf("response %d: missing function call part", i)
+			}
+			if fc.Name != "get_weather" || fc.ID != "call_1" {
+				t.Errorf("response %d: function call = %s/%s, want get_weather/call_1", i, fc.Name, fc.ID)
+			}
+			if diff := cmp.Diff(wantArgs[i], fc.Args); diff != "" {
+				t.Errorf("response %d: args mismatch (-want +got):\n%s", i, diff)
+			}
+			if fc.WillContinue == nil || !*fc.WillContinue {
+				t.Errorf("response %d: WillContinue not set", i)
+			}
+		}
+
+		final := resps[3]
+		if final.Partial || !final.TurnComplete {
+			t.Errorf("final response: Partial = %v, TurnComplete = %v", final.Partial, final.TurnComplete)
+		}
+		if len(final.Content.Parts) != 1 || final.Content.Parts[0].FunctionCall.WillContinue != nil {
+			t.Errorf("final response parts = %+v, want one complete function call", final.Content.Parts)
+		}
+	})
+}
+
+func TestReadStream_StreamSentences(t *testing.T) {
+	newStream := func() *fakeStream {
+		var chunks []openai.ChatCompletionStreamResponse
+		for _, delta := range []string{"Hel", "lo there. How", " are you? I am", " fine; thanks", "!", " Bye"} {
+			chunks = append(chunks, deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: delta}, ""))
+		}
+		chunks = append(chunks, deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop))
+		return &fakeStream{chunks: chunks}
+	}
+
+	tests := []struct {
+		name        string
+		m           *OpenAIModel
+		wantPartial []string
+	}{
+		{
+			name:        "default delimiters",
+			m:           &OpenAIModel{StreamSentences: true},
+			wantPartial: []string{"Hello there.", " How are you?", " I am fine; thanks!", " Bye"},
+		},
+		{
+			name:        "custom delimiters",
+			m:           &OpenAIModel{StreamSentences: true, SentenceDelimiters: ";"},
+			wantPartial: []string{"Hello there. How are you? I am fine;", " thanks! Bye"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := collectStream(t, tt.m, newStream())
+
+			var gotPartial []string
+			for _, resp := range resps[:len(resps)-1] {
+				if !resp.Partial {
+					t.Errorf("intermediate response not marked partial: %+v", resp)
+				}
+				gotPartial = append(gotPartial, resp.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff(tt.wantPartial, gotPartial); diff != "" {
+				t.Errorf("partial texts mismatch (-want +got):\n%s", diff)
+			}
+
+			final := resps[len(resps)-1]
+			if got, want := final.Content.Parts[0].Text, "Hello there. How are you? I am fine; thanks! Bye"; got != want {
+				t.Errorf("final text = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReadStream_ReasoningContent(t *testing.T) {
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{ReasoningContent: "The user "}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{ReasoningContent: "greets me."}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "!"}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop),
+	}}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+
+	var gotPartial []*genai.Part
+	for _, resp := range resps[:len(resps)-1] {
+		gotPartial = append(gotPartial, resp.Content.Parts...)
+	}
+	wantPartial := []*genai.Part{
+		{Text: "The user ", Thought: true},
+		{Text: "greets me.", Thought: true},
+		{Text: "Hello"},
+		{Text: "!"},
+	}
+	if diff := cmp.Diff(wantPartial, gotPartial); diff != "" {
+		t.Errorf("partial parts mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFinal := []*genai.Part{
+		{Text: "The user greets me.", Thought: true},
+		{Text: "Hello!"},
+	}
+	if diff := cmp.Diff(wantFinal, resps[len(resps)-1].Content.Parts); diff != "" {
+		t.Errorf("final parts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_LenientStreamEnd(t *testing.T) {
+	// Ollama-style stream: no finish_reason and no usage chunk
+	newStream := func() *fakeStream {
+		return &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: " there"}, ""),
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		m      *OpenAIModel
+		stream *fakeStream
+		want   genai.FinishReason
+	}{
+		{name: "strict", m: &OpenAIModel{}, stream: newStream(), want: ""},
+		{name: "lenient", m: &OpenAIModel{LenientStreamEnd: true}, stream: newStream(), want: genai.FinishReasonStop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resps := collectStream(t, tt.m, tt.stream)
+			final := resps[len(resps)-1]
+			if final.FinishReason != tt.want {
+				t.Errorf("FinishReason = %q, want %q", final.FinishReason, tt.want)
+			}
+			if !final.TurnComplete {
+				t.Error("final response not marked TurnComplete")
+			}
+			if final.UsageMetadata != nil {
+				t.Errorf("UsageMetadata = %+v, want nil", final.UsageMetadata)
+			}
+		})
+	}
+}
+
+func TestReadStream_NoChoices(t *testing.T) {
+	heartbeat := deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, "")
+	tests := []struct {
+		name   string
+		m      *OpenAIModel
+		chunks []openai.ChatCompletionStreamResponse
+	}{
+		{name: "no chunks", m: &OpenAIModel{}},
+		{name: "chunks without choices", m: &OpenAIModel{}, chunks: []openai.ChatCompletionStreamResponse{{ID: "1"}, {ID: "2"}}},
+		{name: "heartbeat chunks", m: &OpenAIModel{}, chunks: []openai.ChatCompletionStreamResponse{heartbeat, heartbeat}},
+		{name: "lenient", m: &OpenAIModel{LenientStreamEnd: true}, chunks: []openai.ChatCompletionStreamResponse{heartbeat}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resps []*model.LLMResponse
+			var gotErr error
+			tt.m.readStream(context.Background(), &fakeStream{chunks: tt.chunks}, func(resp *model.LLMResponse, err error) bool {
+				if err != nil {
+					gotErr = err
+					return false
+				}
+				resps = append(resps, resp)
+				return true
+			})
+			if !errors.Is(gotErr, ErrNoChoicesInResponse) {
+				t.Errorf("readStream() error = %v, want ErrNoChoicesInResponse", gotErr)
+			}
+			if len(resps) != 0 {
+				t.Errorf("got %d responses, want none", len(resps))
+			}
+		})
+	}
+}
+
+func TestReadStream_Cancellation(t *testing.T) {
+	newStream := func() *fakeStream {
+		return &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "one"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "two"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "three"}, openai.FinishReasonStop),
+		}}
+	}
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := newStream()
+
+		var resps []*model.LLMResponse
+		var errs []error
+		(&OpenAIModel{}).readStream(ctx, stream, func(resp *model.LLMResponse, err error) bool {
+			if err != nil {
+				errs = append(errs, err)
+				return true
+			}
+			resps = append(resps, resp)
+			if len(resps) == 2 {
+				cancel()
+			}
+			return true
+		})
+
+		if len(resps) != 3 {
+			t.Fatalf("got %d responses, want two partials and an interrupted final one", len(resps))
+		}
+		want := &model.LLMResponse{
+			Content:       &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "onetwo"}}},
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{},
+			FinishReason:  genai.FinishReasonOther,
+			TurnComplete:  true,
+			Interrupted:   true,
+		}
+		if diff := cmp.Diff(want, resps[2]); diff != "" {
+			t.Errorf("final response mismatch (-want +got):\n%s", diff)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+			t.Errorf("errors = %v, want a single context.Canceled", errs)
+		}
+		if !stream.closed {
+			t.Error("stream not closed")
+		}
+	})
+
+	t.Run("cancelled after usage", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "one"}, ""),
+			{Usage: &openai.Usage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6}},
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "two"}, ""),
+		}}
+
+		var final *model.LLMResponse
+		(&OpenAIModel{}).readStream(ctx, stream, func(resp *model.LLMResponse, err error) bool {
+			if resp != nil && resp.Interrupted {
+				final = resp
+			}
+			if resp != nil && resp.Partial && resp.Content.Parts[0].Text == "two" {
+				cancel()
+			}
+			return err == nil
+		})
+
+		if final == nil {
+			t.Fatal("no interrupted response")
+		}
+		wantUsage := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 5, CandidatesTokenCount: 1, TotalTokenCount: 6}
+		if diff := cmp.Diff(wantUsage, final.UsageMetadata); diff != "" {
+			t.Errorf("usage mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("consumer stops", func(t *testing.T) {
+		stream := newStream()
+		calls := 0
+		(&OpenAIModel{}).readStream(context.Background(), stream, func(*model.LLMResponse, error) bool {
+			calls++
+			return false
+		})
+
+		if calls != 1 {
+			t.Errorf("yield called %d times, want 1", calls)
+		}
+		if !stream.closed {
+			t.Error("stream not closed")
+		}
+	})
+}
+
+func TestOpenAIModel_Hooks(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   &genai.GenerateContentConfig{},
+	}
+
+	tests := []struct {
+		name   string
+		stream bool
+		m      func(t *testing.T) *OpenAIModel
+	}{
+		{
+			name:   "generate",
+			stream: false,
+			m: func(t *testing.T) *OpenAIModel {
+				s, m := newFakeServer(t)
+				s.response = openai.ChatCompletionResponse{
+					Choices: []openai.ChatCompletionChoice{{
+						Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Hi"},
+						FinishReason: openai.FinishReasonStop,
+					}},
+				}
+				return m
+			},
+		},
+		{
+			name:   "generateStream",
+			stream: true,
+			m: func(t *testing.T) *OpenAIModel {
+				s, m := newFakeServer(t)
+				s.chunks = []openai.ChatCompletionStreamResponse{
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "H"}, ""),
+					deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "i"}, openai.FinishReasonStop),
+				}
+				return m
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.m(t)
+			var gotReqs []*openai.ChatCompletionRequest
+			var gotResps []*model.LLMResponse
+			m.OnRequest = func(ctx context.Context, req *openai.ChatCompletionRequest) {
+				gotReqs = append(gotReqs, req)
+			}
+			m.OnResponse = func(ctx context.Context, resp *model.LLMResponse, err error) {
+				if err != nil {
+					t.Errorf("OnResponse() got error %v", err)
+				}
+				gotResps = append(gotResps, resp)
+			}
+
+			var last *model.LLMResponse
+			for resp, err := range m.GenerateContent(context.Background(), req, tt.stream) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				last = resp
+			}
+
+			if len(gotReqs) != 1 {
+				t.Fatalf("OnRequest called %d times, want 1", len(gotReqs))
+			}
+			if gotReqs[0].Model != "gpt-4o" || gotReqs[0].Stream != tt.stream {
+				t.Errorf("OnRequest got model %q stream %v", gotReqs[0].Model, gotReqs[0].Stream)
+			}
+			if len(gotResps) != 1 || gotResps[0] != last {
+				t.Errorf("OnResponse got %v, want only the final response", gotResps)
+			}
+			if got := last.Content.Parts[0].Text; got != "Hi" {
+				t.Errorf("final text = %q, want %q", got, "Hi")
+			}
+		})
+	}
+}
+
+func TestOpenAIModel_OnResponseError(t *testing.T) {
+	m := &OpenAIModel{ModelName: "gpt-4o"}
+	var gotErr error
+	m.OnResponse = func(ctx context.Context, resp *model.LLMResponse, err error) {
+		gotErr = err
+	}
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   &genai.GenerateContentConfig{ResponseJsonSchema: map[string]any{"type": "object"}},
+	}
+	for _, err := range m.GenerateContent(context.Background(), req, false) {
+		if err == nil {
+			t.Fatal("GenerateContent() error = nil, want error")
+		}
+	}
+	if gotErr == nil {
+		t.Error("OnResponse was not called with the error")
+	}
+}
+
+// Benchmark tests
+func TestToolCallBuilder(t *testing.T) {
+	// Simulate the streaming scenario where tool call comes in multiple chunks
+	builder := &toolCallBuilder{
+		id:   "call_123",
+		name: "get_weather",
+		args: "",
+	}
+
+	// First chunk: ID and name
+	builder.id = "call_123"
+	builder.name = "get_weather"
+
+	// Second chunk: partial arguments
+	builder.args += `{"location":`
 
 	// Third chunk: more arguments
 	builder.args += `"Paris"`
@@ -943,9 +2343,10 @@ func BenchmarkToOpenAIChatCompletionMessage(b *testing.B) {
 		Role:  "user",
 		Parts: []*genai.Part{{Text: "Hello, world!"}},
 	}
+	m := &OpenAIModel{}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = toOpenAIChatCompletionMessage(content)
+		_, _ = m.toOpenAIChatCompletionMessage(content)
 	}
 }
 
@@ -968,6 +2369,323 @@ func BenchmarkConvertChatCompletionResponse(b *testing.B) {
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = convertChatCompletionResponse(resp)
+		_, _ = convertChatCompletionResponse(resp, convertFinishReason)
+	}
+}
+
+func TestGenerateContent_StreamUsage(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			s, m := newFakeServer(t)
+			if enabled {
+				s.chunks = append(s.chunks, openai.ChatCompletionStreamResponse{
+					Choices: []openai.ChatCompletionStreamChoice{},
+					Usage:   &openai.Usage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6},
+				})
+			}
+			m.StreamUsage = enabled
+
+			var final *model.LLMResponse
+			for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+			}, true) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				final = resp
+			}
+
+			var body map[string]any
+			s.lastBody(t, &body)
+			options, ok := body["stream_options"]
+			if enabled {
+				if diff := cmp.Diff(map[string]any{"include_usage": true}, options); diff != "" {
+					t.Errorf("stream_options mismatch (-want +got):\n%s", diff)
+				}
+				want := &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 5, CandidatesTokenCount: 1, TotalTokenCount: 6}
+				if diff := cmp.Diff(want, final.UsageMetadata); diff != "" {
+					t.Errorf("UsageMetadata mismatch (-want +got):\n%s", diff)
+				}
+			} else {
+				if ok {
+					t.Errorf("stream_options = %v, want it omitted", options)
+				}
+				if final.UsageMetadata != nil {
+					t.Errorf("UsageMetadata = %+v, want nil", final.UsageMetadata)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertUsage_ReasoningTokens(t *testing.T) {
+	usage := openai.Usage{
+		PromptTokens:            12,
+		CompletionTokens:        40,
+		TotalTokens:             52,
+		CompletionTokensDetails: &openai.CompletionTokensDetails{ReasoningTokens: 32},
+	}
+	want := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     12,
+		CandidatesTokenCount: 40,
+		TotalTokenCount:      52,
+		ThoughtsTokenCount:   32,
+	}
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "4"}
+
+	resp, err := convertChatCompletionResponse(&openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: message, FinishReason: openai.FinishReasonStop}},
+		Usage:   usage,
+	}, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if diff := cmp.Diff(want, resp.UsageMetadata); diff != "" {
+		t.Errorf("non-streaming UsageMetadata mismatch (-want +got):\n%s", diff)
+	}
+
+	resps := collectStream(t, &OpenAIModel{}, &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "4"}, openai.FinishReasonStop),
+		{Choices: []openai.ChatCompletionStreamChoice{}, Usage: &usage},
+	}})
+	if diff := cmp.Diff(want, resps[len(resps)-1].UsageMetadata); diff != "" {
+		t.Errorf("streaming UsageMetadata mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_MaxTokens(t *testing.T) {
+	tests := []struct {
+		name            string
+		modelMaxTokens  int
+		maxOutputTokens int32
+		want            int
+	}{
+		{name: "zero sends no limit", want: 0},
+		{name: "positive", maxOutputTokens: 256, want: 256},
+		{name: "explicit max for zero", modelMaxTokens: 32000, want: 32000},
+		{name: "request wins over explicit max", modelMaxTokens: 32000, maxOutputTokens: 256, want: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", MaxTokens: tt.modelMaxTokens}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+				Config:   &genai.GenerateContentConfig{MaxOutputTokens: tt.maxOutputTokens},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.MaxTokens != tt.want {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelVersionMetadata(t *testing.T) {
+	want := map[string]any{
+		ModelVersionMetadataKey:      "gpt-4o-2024-08-06",
+		SystemFingerprintMetadataKey: "fp_abc123",
+	}
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Hi"}
+
+	resp, err := convertChatCompletionResponse(&openai.ChatCompletionResponse{
+		Model:             "gpt-4o-2024-08-06",
+		SystemFingerprint: "fp_abc123",
+		Choices:           []openai.ChatCompletionChoice{{Message: message, FinishReason: openai.FinishReasonStop}},
+	}, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if diff := cmp.Diff(want, resp.CustomMetadata); diff != "" {
+		t.Errorf("non-streaming CustomMetadata mismatch (-want +got):\n%s", diff)
+	}
+
+	first := deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "H"}, "")
+	first.Model = "gpt-4o-2024-08-06"
+	last := deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "i"}, openai.FinishReasonStop)
+	last.Model = "gpt-4o-2024-08-06"
+	last.SystemFingerprint = "fp_abc123"
+	resps := collectStream(t, &OpenAIModel{}, &fakeStream{chunks: []openai.ChatCompletionStreamResponse{first, last}})
+	if diff := cmp.Diff(want, resps[len(resps)-1].CustomMetadata); diff != "" {
+		t.Errorf("streaming CustomMetadata mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToolCallTurnIsDistinguishable(t *testing.T) {
+	toolCall := openai.ToolCall{
+		ID:       "call_1",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+	}
+	index := 0
+	streamed := toolCall
+	streamed.Index = &index
+
+	tests := []struct {
+		name   string
+		stream bool
+		choice openai.FinishReason
+		want   any
+	}{
+		{name: "tool calls", choice: openai.FinishReasonToolCalls, want: "tool_calls"},
+		{name: "streamed tool calls", stream: true, choice: openai.FinishReasonToolCalls, want: "tool_calls"},
+		{name: "completed answer", choice: openai.FinishReasonStop},
+		{name: "streamed completed answer", stream: true, choice: openai.FinishReasonStop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *model.LLMResponse
+			if tt.stream {
+				delta := openai.ChatCompletionStreamChoiceDelta{Content: "Done."}
+				if tt.choice == openai.FinishReasonToolCalls {
+					delta = openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{streamed}}
+				}
+				resps := collectStream(t, &OpenAIModel{}, &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+					deltaChunk(delta, tt.choice),
+				}})
+				resp = resps[len(resps)-1]
+			} else {
+				message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Done."}
+				if tt.choice == openai.FinishReasonToolCalls {
+					message = openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{toolCall}}
+				}
+				var err error
+				resp, err = convertChatCompletionResponse(&openai.ChatCompletionResponse{
+					Choices: []openai.ChatCompletionChoice{{Message: message, FinishReason: tt.choice}},
+				}, convertFinishReason)
+				if err != nil {
+					t.Fatalf("convertChatCompletionResponse() error = %v", err)
+				}
+			}
+
+			if resp.FinishReason != genai.FinishReasonStop || !resp.TurnComplete {
+				t.Errorf("FinishReason = %q, TurnComplete = %v, want STOP and true", resp.FinishReason, resp.TurnComplete)
+			}
+			if got := resp.CustomMetadata[FinishReasonMetadataKey]; got != tt.want {
+				t.Errorf("CustomMetadata[%q] = %v, want %v", FinishReasonMetadataKey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStream_MultipleCandidates(t *testing.T) {
+	choice := func(index int, delta openai.ChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) openai.ChatCompletionStreamChoice {
+		return openai.ChatCompletionStreamChoice{Index: index, Delta: delta, FinishReason: finishReason}
+	}
+	callIndex := 0
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		{Choices: []openai.ChatCompletionStreamChoice{choice(0, openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, "")}},
+		{Choices: []openai.ChatCompletionStreamChoice{choice(1, openai.ChatCompletionStreamChoiceDelta{Content: "Hi"}, "")}},
+		{Choices: []openai.ChatCompletionStreamChoice{
+			choice(1, openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+				Index:    &callIndex,
+				ID:       "call_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "wave", Arguments: `{"times":2}`},
+			}}}, ""),
+			choice(0, openai.ChatCompletionStreamChoiceDelta{Content: " there"}, ""),
+		}},
+		{Choices: []openai.ChatCompletionStreamChoice{choice(0, openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop)}},
+		{Choices: []openai.ChatCompletionStreamChoice{choice(1, openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonToolCalls)}},
+	}}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+
+	var partials []string
+	for _, resp := range resps[:len(resps)-1] {
+		partials = append(partials, resp.Content.Parts[0].Text)
+	}
+	if diff := cmp.Diff([]string{"Hello", " there"}, partials); diff != "" {
+		t.Errorf("partial texts mismatch (-want +got):\n%s", diff)
+	}
+
+	final := resps[len(resps)-1]
+	if got := final.Content.Parts[0].Text; got != "Hello there" {
+		t.Errorf("final text = %q, want %q", got, "Hello there")
+	}
+	want := []*genai.Candidate{
+		{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "Hello there"}}},
+			FinishReason: genai.FinishReasonStop,
+		},
+		{
+			Content: &genai.Content{Role: "model", Parts: []*genai.Part{
+				{Text: "Hi"},
+				{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "wave", Args: map[string]any{"times": float64(2)}}},
+			}},
+			FinishReason: genai.FinishReasonStop,
+			Index:        1,
+		},
+	}
+	if diff := cmp.Diff(want, final.CustomMetadata[CandidatesMetadataKey]); diff != "" {
+		t.Errorf("candidates mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_StructuredContent(t *testing.T) {
+	content := &genai.Content{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}
+
+	tests := []struct {
+		name       string
+		structured bool
+		want       openai.ChatCompletionMessage
+	}{
+		{
+			name: "plain string",
+			want: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: "Hello"},
+		},
+		{
+			name:       "structured",
+			structured: true,
+			want: openai.ChatCompletionMessage{
+				Role:         openai.ChatMessageRoleUser,
+				MultiContent: []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "Hello"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{StructuredContent: tt.structured}
+			msgs, err := m.toOpenAIChatCompletionMessage(content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if diff := cmp.Diff([]openai.ChatCompletionMessage{tt.want}, msgs); diff != "" {
+				t.Errorf("toOpenAIChatCompletionMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionMessage_ToolCallOmitsContent(t *testing.T) {
+	for _, parts := range [][]*genai.Part{
+		{{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "lookup", Args: map[string]any{"id": 1}}}},
+		{
+			{Text: "  "},
+			{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "lookup", Args: map[string]any{"id": 1}}},
+		},
+	} {
+		msgs, err := (&OpenAIModel{}).toOpenAIChatCompletionMessage(&genai.Content{Role: "model", Parts: parts})
+		if err != nil {
+			t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+		}
+		data, err := json.Marshal(msgs[0])
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if content, ok := got["content"]; ok {
+			t.Errorf("serialized message has content %q, want it omitted: %s", content, data)
+		}
+		if _, ok := got["tool_calls"]; !ok {
+			t.Errorf("serialized message has no tool_calls: %s", data)
+		}
 	}
 }
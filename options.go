@@ -0,0 +1,12 @@
+package openai
+
+// Option configures an OpenAIModel at construction.
+type Option func(*OpenAIModel)
+
+// WithValidateRequests checks every built request locally before it is sent.
+// See OpenAIModel.ValidateRequests.
+func WithValidateRequests() Option {
+	return func(o *OpenAIModel) {
+		o.ValidateRequests = true
+	}
+}
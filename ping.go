@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// PingError reports why Ping failed. StatusCode is the HTTP status the
+// endpoint answered with, or zero if it could not be reached at all.
+type PingError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *PingError) Error() string {
+	switch {
+	case e.StatusCode == 0:
+		return fmt.Sprintf("endpoint unreachable: %v", e.Err)
+	case e.Unauthorized():
+		return fmt.Sprintf("endpoint rejected credentials (status %d): %v", e.StatusCode, e.Err)
+	default:
+		return fmt.Sprintf("endpoint failed (status %d): %v", e.StatusCode, e.Err)
+	}
+}
+
+func (e *PingError) Unwrap() error {
+	return e.Err
+}
+
+// Unauthorized reports whether the endpoint rejected the API key.
+func (e *PingError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// Ping checks that the endpoint is reachable and accepts the model's
+// credentials by listing its models, which costs no tokens. It returns nil on
+// success and a *PingError otherwise.
+func (o *OpenAIModel) Ping(ctx context.Context) error {
+	_, err := o.Client.ListModels(ctx)
+	if err == nil {
+		return nil
+	}
+	pingErr := &PingError{Err: err}
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		pingErr.StatusCode = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		pingErr.StatusCode = reqErr.HTTPStatusCode
+	}
+	return pingErr
+}
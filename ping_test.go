@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantErr          bool
+		wantStatus       int
+		wantUnauthorized bool
+	}{
+		{name: "success", status: http.StatusOK, body: `{"object":"list","data":[{"id":"gpt-4o"}]}`},
+		{
+			name:             "unauthorized",
+			status:           http.StatusUnauthorized,
+			body:             `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`,
+			wantErr:          true,
+			wantStatus:       http.StatusUnauthorized,
+			wantUnauthorized: true,
+		},
+		{
+			name:       "server error",
+			status:     http.StatusServiceUnavailable,
+			body:       `upstream unavailable`,
+			wantErr:    true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			s.handle("/models", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			err := m.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			var pingErr *PingError
+			if !errors.As(err, &pingErr) {
+				t.Fatalf("Ping() error = %v, want *PingError", err)
+			}
+			if pingErr.StatusCode != tt.wantStatus || pingErr.Unauthorized() != tt.wantUnauthorized {
+				t.Errorf("PingError = %+v, want status %d, unauthorized %v", pingErr, tt.wantStatus, tt.wantUnauthorized)
+			}
+		})
+	}
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	cfg := openai.DefaultConfig("test")
+	cfg.BaseURL = server.URL
+	server.Close()
+	m := NewOpenAIModel("gpt-4o", cfg)
+
+	var pingErr *PingError
+	if err := m.Ping(context.Background()); !errors.As(err, &pingErr) || pingErr.StatusCode != 0 {
+		t.Errorf("Ping() error = %v, want *PingError without a status", err)
+	}
+}
@@ -0,0 +1,50 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// Metadata keys under which LLMResponse.CustomMetadata reports, as ints, how
+// many tokens of a predicted output the model accepted and rejected.
+const (
+	AcceptedPredictionTokensMetadataKey = "accepted_prediction_tokens"
+	RejectedPredictionTokensMetadataKey = "rejected_prediction_tokens"
+)
+
+// predictionKey is the context key for the per-request predicted output.
+type predictionKey struct{}
+
+// ContextWithPrediction returns a copy of ctx whose requests pass prediction
+// as the expected response. When most of the response is known in advance,
+// such as a file being regenerated with small edits, the model can skip
+// generating the matching tokens and respond faster.
+func ContextWithPrediction(ctx context.Context, prediction string) context.Context {
+	return context.WithValue(ctx, predictionKey{}, prediction)
+}
+
+// prediction returns the predicted output to send with requests made under
+// ctx, or nil when none was given.
+func prediction(ctx context.Context) *openai.Prediction {
+	content, ok := ctx.Value(predictionKey{}).(string)
+	if !ok || content == "" {
+		return nil
+	}
+	return &openai.Prediction{Type: "content", Content: content}
+}
+
+// applyPredictionUsage records the accepted and rejected prediction token
+// counts of usage in resp, if the backend reported any.
+func applyPredictionUsage(resp *model.LLMResponse, usage *openai.Usage) {
+	if usage == nil || usage.CompletionTokensDetails == nil {
+		return
+	}
+	details := usage.CompletionTokensDetails
+	if details.AcceptedPredictionTokens == 0 && details.RejectedPredictionTokens == 0 {
+		return
+	}
+	setCustomMetadata(resp, AcceptedPredictionTokensMetadataKey, details.AcceptedPredictionTokens)
+	setCustomMetadata(resp, RejectedPredictionTokensMetadataKey, details.RejectedPredictionTokens)
+}
@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_Prediction(t *testing.T) {
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Rename x to count"}}}},
+	}
+	m := &OpenAIModel{ModelName: "gpt-4o"}
+
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+	if got.Prediction != nil {
+		t.Errorf("Prediction = %+v, want nil by default", got.Prediction)
+	}
+
+	ctx := ContextWithPrediction(context.Background(), "x := 1\nfmt.Println(x)\n")
+	got, err = m.toOpenAIChatCompletionRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+	want := &openai.Prediction{Type: "content", Content: "x := 1\nfmt.Println(x)\n"}
+	if diff := cmp.Diff(want, got.Prediction); diff != "" {
+		t.Errorf("Prediction mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertChatCompletionResponse_PredictionUsage(t *testing.T) {
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "count := 1"},
+			FinishReason: openai.FinishReasonStop,
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     20,
+			CompletionTokens: 10,
+			TotalTokens:      30,
+			CompletionTokensDetails: &openai.CompletionTokensDetails{
+				AcceptedPredictionTokens: 7,
+				RejectedPredictionTokens: 2,
+			},
+		},
+	}
+
+	got, err := convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	want := map[string]any{
+		AcceptedPredictionTokensMetadataKey: 7,
+		RejectedPredictionTokensMetadataKey: 2,
+	}
+	if diff := cmp.Diff(want, got.CustomMetadata); diff != "" {
+		t.Errorf("CustomMetadata mismatch (-want +got):\n%s", diff)
+	}
+
+	resp.Usage.CompletionTokensDetails = nil
+	got, err = convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if got.CustomMetadata != nil {
+		t.Errorf("CustomMetadata = %v, want nil without prediction usage", got.CustomMetadata)
+	}
+}
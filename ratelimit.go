@@ -0,0 +1,58 @@
+package openai
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// rateLimitDoer reports the rate limit headers of every response, including
+// 429 errors, to the model's OnRateLimit callback.
+type rateLimitDoer struct {
+	doer  openai.HTTPDoer
+	model *OpenAIModel
+}
+
+func (d rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	if err == nil && d.model.OnRateLimit != nil && hasRateLimitHeaders(resp.Header) {
+		d.model.OnRateLimit(parseRateLimitHeaders(resp.Header))
+	}
+	return resp, err
+}
+
+// hasRateLimitHeaders reports whether header carries any x-ratelimit-* header,
+// which many OpenAI-compatible backends never send.
+func hasRateLimitHeaders(header http.Header) bool {
+	for _, name := range []string{
+		"x-ratelimit-limit-requests",
+		"x-ratelimit-limit-tokens",
+		"x-ratelimit-remaining-requests",
+		"x-ratelimit-remaining-tokens",
+		"x-ratelimit-reset-requests",
+		"x-ratelimit-reset-tokens",
+	} {
+		if header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRateLimitHeaders reads the x-ratelimit-* headers of a response. Counts
+// that are missing or malformed are left zero.
+func parseRateLimitHeaders(header http.Header) openai.RateLimitHeaders {
+	count := func(name string) int {
+		n, _ := strconv.Atoi(header.Get(name))
+		return n
+	}
+	return openai.RateLimitHeaders{
+		LimitRequests:     count("x-ratelimit-limit-requests"),
+		LimitTokens:       count("x-ratelimit-limit-tokens"),
+		RemainingRequests: count("x-ratelimit-remaining-requests"),
+		RemainingTokens:   count("x-ratelimit-remaining-tokens"),
+		ResetRequests:     openai.ResetTime(header.Get("x-ratelimit-reset-requests")),
+		ResetTokens:       openai.ResetTime(header.Get("x-ratelimit-reset-tokens")),
+	}
+}
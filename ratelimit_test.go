@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// rateLimitTransport injects rate limit headers into every response it
+// receives.
+type rateLimitTransport struct {
+	header http.Header
+}
+
+func (t rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range t.header {
+		resp.Header[name] = values
+	}
+	return resp, nil
+}
+
+func TestGenerateContent_OnRateLimit(t *testing.T) {
+	injected := http.Header{}
+	injected.Set("x-ratelimit-limit-requests", "500")
+	injected.Set("x-ratelimit-remaining-requests", "499")
+	injected.Set("x-ratelimit-remaining-tokens", "29000")
+	injected.Set("x-ratelimit-reset-requests", "120ms")
+	want := openai.RateLimitHeaders{
+		LimitRequests:     500,
+		RemainingRequests: 499,
+		RemainingTokens:   29000,
+		ResetRequests:     "120ms",
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		header  http.Header
+		wantErr bool
+		want    []openai.RateLimitHeaders
+	}{
+		{name: "success", status: http.StatusOK, header: injected, want: []openai.RateLimitHeaders{want}},
+		{name: "rate limited", status: http.StatusTooManyRequests, header: injected, wantErr: true, want: []openai.RateLimitHeaders{want}},
+		{name: "no headers", status: http.StatusOK, header: http.Header{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newFakeServer(t)
+			if tt.status != http.StatusOK {
+				s.handle("/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests"}}`))
+				})
+			}
+
+			cfg := s.config()
+			cfg.HTTPClient = &http.Client{Transport: rateLimitTransport{header: tt.header}}
+			m := NewOpenAIModel("gpt-4o", cfg)
+			var mu sync.Mutex
+			var got []openai.RateLimitHeaders
+			m.OnRateLimit = func(limits openai.RateLimitHeaders) {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, limits)
+			}
+
+			var gotErr error
+			for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hi"}}}},
+			}, false) {
+				gotErr = err
+			}
+
+			var apiErr *openai.APIError
+			if tt.wantErr != errors.As(gotErr, &apiErr) {
+				t.Errorf("GenerateContent() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("OnRateLimit mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// RawResponsesMetadataKey is the LLMResponse.CustomMetadata key under which
+// the final response of a call stores, as [][]byte, the exact bodies the API
+// returned when OpenAIModel.CaptureRawResponses is set: the JSON of a
+// non-streaming call or the server-sent events of a streaming one. There is
+// one body per successful HTTP response, so fanned-out candidates and
+// reconnected streams carry several, in the order they were received.
+const RawResponsesMetadataKey = "raw_responses"
+
+// rawCaptureKey is the context key under which a call's rawCapture travels
+// from GenerateContent to rawResponseDoer.
+type rawCaptureKey struct{}
+
+// rawCapture collects the response bodies of one call.
+type rawCapture struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+// add starts a new body and returns its index.
+func (c *rawCapture) add() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodies = append(c.bodies, []byte{})
+	return len(c.bodies) - 1
+}
+
+func (c *rawCapture) write(i int, p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodies[i] = append(c.bodies[i], p...)
+}
+
+// snapshot returns a copy of the bodies read so far.
+func (c *rawCapture) snapshot() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bodies := make([][]byte, len(c.bodies))
+	for i, body := range c.bodies {
+		bodies[i] = append([]byte(nil), body...)
+	}
+	return bodies
+}
+
+// captureRawResponses returns ctx carrying a fresh rawCapture and wraps yield
+// to store the bodies captured so far on every non-partial response. Without
+// CaptureRawResponses both are returned unchanged.
+func (o *OpenAIModel) captureRawResponses(ctx context.Context, yield func(*model.LLMResponse, error) bool) (context.Context, func(*model.LLMResponse, error) bool) {
+	if !o.CaptureRawResponses {
+		return ctx, yield
+	}
+	capture := &rawCapture{}
+	return context.WithValue(ctx, rawCaptureKey{}, capture), func(resp *model.LLMResponse, err error) bool {
+		if err == nil && !resp.Partial {
+			setCustomMetadata(resp, RawResponsesMetadataKey, capture.snapshot())
+		}
+		return yield(resp, err)
+	}
+}
+
+// rawResponseDoer tees the body of every successful response into the
+// rawCapture of the request's context, if any.
+type rawResponseDoer struct {
+	doer openai.HTTPDoer
+}
+
+func (d rawResponseDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	capture, ok := req.Context().Value(rawCaptureKey{}).(*rawCapture)
+	if err != nil || !ok || resp.StatusCode >= http.StatusBadRequest {
+		return resp, err
+	}
+	resp.Body = &teeBody{ReadCloser: resp.Body, capture: capture, index: capture.add()}
+	return resp, nil
+}
+
+// teeBody records everything read from a response body.
+type teeBody struct {
+	io.ReadCloser
+	capture *rawCapture
+	index   int
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture.write(b.index, p[:n])
+	return n, err
+}
@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+func TestGenerateContent_CaptureRawResponses(t *testing.T) {
+	s, m := newFakeServer(t)
+	m.CaptureRawResponses = true
+	s.response = openai.ChatCompletionResponse{
+		ID:      "chatcmpl-1",
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "Sunny"}, FinishReason: "stop"}},
+	}
+	s.chunks = []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Sun"}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "ny"}, openai.FinishReasonStop),
+	}
+
+	body, err := json.Marshal(s.response)
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	wantJSON := string(body) + "\n"
+	var wantSSE string
+	for _, chunk := range s.chunks {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			t.Fatalf("failed to marshal chunk: %v", err)
+		}
+		wantSSE += fmt.Sprintf("data: %s\n\n", data)
+	}
+	wantSSE += "data: [DONE]\n\n"
+
+	for _, tt := range []struct {
+		name   string
+		stream bool
+		want   string
+	}{
+		{name: "non-streaming", want: wantJSON},
+		{name: "streaming", stream: true, want: wantSSE},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var final *model.LLMResponse
+			for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), tt.stream) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				if resp.Partial {
+					if _, ok := resp.CustomMetadata[RawResponsesMetadataKey]; ok {
+						t.Error("partial response carries raw responses")
+					}
+					continue
+				}
+				final = resp
+			}
+
+			bodies, ok := final.CustomMetadata[RawResponsesMetadataKey].([][]byte)
+			if !ok || len(bodies) != 1 {
+				t.Fatalf("CustomMetadata[%q] = %v, want one body", RawResponsesMetadataKey, final.CustomMetadata[RawResponsesMetadataKey])
+			}
+			if got := string(bodies[0]); got != tt.want {
+				t.Errorf("raw body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateContent_CaptureRawResponsesDisabled(t *testing.T) {
+	s, m := newFakeServer(t)
+	s.response = openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "Sunny"}, FinishReason: "stop"}},
+	}
+	for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if _, ok := resp.CustomMetadata[RawResponsesMetadataKey]; ok {
+			t.Error("raw responses captured without CaptureRawResponses")
+		}
+	}
+}
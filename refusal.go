@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// RefusalMetadataKey is the LLMResponse.CustomMetadata key under which the
+// model's refusal message is stored as a string when it declined to answer.
+const RefusalMetadataKey = "refusal"
+
+// RefusalError reports that the model declined to answer, which structured
+// output requests signal through a dedicated refusal field.
+type RefusalError struct {
+	Refusal string
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("model refused: %s", e.Refusal)
+}
+
+// applyRefusal marks resp as refused: the refusal becomes a text part, is
+// stored under RefusalMetadataKey, and the finish reason becomes
+// FinishReasonSafety.
+func applyRefusal(resp *model.LLMResponse, refusal string) {
+	if refusal == "" {
+		return
+	}
+	resp.Content.Parts = append(resp.Content.Parts, &genai.Part{Text: refusal})
+	resp.FinishReason = genai.FinishReasonSafety
+	setCustomMetadata(resp, RefusalMetadataKey, refusal)
+}
+
+// refusalError returns a RefusalError for a refused resp when RefusalAsError
+// is set, and nil otherwise.
+func (o *OpenAIModel) refusalError(resp *model.LLMResponse) error {
+	if !o.RefusalAsError {
+		return nil
+	}
+	if refusal, ok := resp.CustomMetadata[RefusalMetadataKey].(string); ok {
+		return &RefusalError{Refusal: refusal}
+	}
+	return nil
+}
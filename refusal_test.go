@@ -0,0 +1,74 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+const testRefusal = "I'm sorry, I can't help with that."
+
+func TestConvertChatCompletionResponse_Refusal(t *testing.T) {
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Refusal: testRefusal},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}
+
+	got, err := convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if diff := cmp.Diff([]*genai.Part{{Text: testRefusal}}, got.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+	if got.FinishReason != genai.FinishReasonSafety {
+		t.Errorf("FinishReason = %q, want %q", got.FinishReason, genai.FinishReasonSafety)
+	}
+	if got.CustomMetadata[RefusalMetadataKey] != testRefusal {
+		t.Errorf("refusal metadata = %v, want %q", got.CustomMetadata[RefusalMetadataKey], testRefusal)
+	}
+}
+
+func TestGenerateContent_RefusalAsError(t *testing.T) {
+	s, m := newFakeServer(t)
+	s.response = openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Refusal: testRefusal},
+			FinishReason: openai.FinishReasonStop,
+		}},
+	}
+	m.RefusalAsError = true
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}
+	for _, err := range m.GenerateContent(context.Background(), req, false) {
+		var refusalErr *RefusalError
+		if !errors.As(err, &refusalErr) || refusalErr.Refusal != testRefusal {
+			t.Errorf("GenerateContent() error = %v, want RefusalError", err)
+		}
+	}
+}
+
+func TestReadStream_Refusal(t *testing.T) {
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Refusal: "I'm sorry, "}, ""),
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Refusal: "I can't help with that."}, openai.FinishReasonStop),
+	}}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+	final := resps[len(resps)-1]
+	if diff := cmp.Diff([]*genai.Part{{Text: testRefusal}}, final.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+	if final.FinishReason != genai.FinishReasonSafety {
+		t.Errorf("FinishReason = %q, want %q", final.FinishReason, genai.FinishReasonSafety)
+	}
+}
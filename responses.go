@@ -0,0 +1,529 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+var _ model.LLM = &OpenAIResponsesModel{}
+
+// defaultResponsesBaseURL is the OpenAI API root used when no base URL is set.
+const defaultResponsesBaseURL = "https://api.openai.com/v1"
+
+// OpenAIResponsesModel implements model.LLM on the OpenAI Responses API
+// instead of chat completions. go-openai has no Responses client, so requests
+// are sent over plain HTTP. A streaming call reads the API's server-sent
+// events, yielding text and reasoning summary deltas as partial responses
+// before the final response.
+type OpenAIResponsesModel struct {
+	ModelName string
+
+	// BuiltinTools lists OpenAI built-in tools, such as BuiltinWebSearch, to
+	// offer the model alongside the function tools of each request. OpenAI
+	// runs them itself and folds their results into the answer, so their
+	// calls are not returned as function calls.
+	BuiltinTools []BuiltinTool
+
+	client responsesClient
+}
+
+// NewOpenAIResponsesModel returns a Responses API model authenticating with
+// apiKey. An empty baseURL uses the OpenAI API.
+func NewOpenAIResponsesModel(modelName, apiKey, baseURL string) *OpenAIResponsesModel {
+	if baseURL == "" {
+		baseURL = defaultResponsesBaseURL
+	}
+	return &OpenAIResponsesModel{
+		ModelName: modelName,
+		client: &httpResponsesClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/"),
+			apiKey:     apiKey,
+			httpClient: http.DefaultClient,
+		},
+	}
+}
+
+// Name implements model.LLM.
+func (o *OpenAIResponsesModel) Name() string {
+	return o.ModelName
+}
+
+// GenerateContent implements model.LLM.
+func (o *OpenAIResponsesModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		respReq, err := o.toResponsesRequest(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		if stream {
+			respReq.Stream = true
+			events, err := o.client.StreamResponse(ctx, respReq)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			defer events.Close()
+			readResponsesStream(events, yield)
+			return
+		}
+
+		resp, err := o.client.CreateResponse(ctx, respReq)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		yield(convertResponsesResponse(resp), nil)
+	}
+}
+
+// readResponsesStream consumes events, yielding text and reasoning summary
+// deltas as partial responses and the response carried by the terminal event
+// as the final one.
+func readResponsesStream(events responsesStream, yield func(*model.LLMResponse, error) bool) {
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			yield(nil, errors.New("responses stream ended before the response completed"))
+			return
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		switch event.Type {
+		case "response.output_text.delta":
+			if !yield(partialTextResponse(genai.RoleModel, event.Delta), nil) {
+				return
+			}
+		case "response.reasoning_summary_text.delta":
+			if !yield(partialThoughtResponse(genai.RoleModel, event.Delta), nil) {
+				return
+			}
+		case "response.completed", "response.incomplete":
+			if event.Response == nil {
+				yield(nil, fmt.Errorf("%s event without a response", event.Type))
+				return
+			}
+			yield(convertResponsesResponse(event.Response), nil)
+			return
+		case "response.failed":
+			apiErr := &openai.APIError{Message: "response failed"}
+			if event.Response != nil && event.Response.Error != nil {
+				apiErr.Code = event.Response.Error.Code
+				apiErr.Message = event.Response.Error.Message
+			}
+			yield(nil, apiErr)
+			return
+		case "error":
+			yield(nil, &openai.APIError{Code: event.Code, Message: event.Message})
+			return
+		}
+	}
+}
+
+// responsesClient sends Responses API requests.
+type responsesClient interface {
+	CreateResponse(ctx context.Context, req *responsesRequest) (*responsesResponse, error)
+	StreamResponse(ctx context.Context, req *responsesRequest) (responsesStream, error)
+}
+
+// responsesStream is the stream of events of a streaming Responses API call.
+// Recv returns io.EOF once the stream ends.
+type responsesStream interface {
+	Recv() (responsesEvent, error)
+	Close() error
+}
+
+// responsesEvent is a server-sent event of a streaming call. Type selects
+// which fields apply: deltas carry Delta, terminal response events carry
+// Response, and "error" events carry Code and Message.
+type responsesEvent struct {
+	Type     string             `json:"type"`
+	Delta    string             `json:"delta,omitempty"`
+	Response *responsesResponse `json:"response,omitempty"`
+	Code     string             `json:"code,omitempty"`
+	Message  string             `json:"message,omitempty"`
+}
+
+type responsesRequest struct {
+	Model           string           `json:"model"`
+	Input           []responsesItem  `json:"input"`
+	Instructions    string           `json:"instructions,omitempty"`
+	Tools           []responsesTool  `json:"tools,omitempty"`
+	Temperature     *float32         `json:"temperature,omitempty"`
+	TopP            *float32         `json:"top_p,omitempty"`
+	MaxOutputTokens int              `json:"max_output_tokens,omitempty"`
+	Text            *responsesFormat `json:"text,omitempty"`
+	Stream          bool             `json:"stream,omitempty"`
+}
+
+// responsesItem is an input or output item. Type selects which fields apply:
+// "message" uses Role and Content, "function_call" uses CallID, Name and
+// Arguments, "function_call_output" uses CallID and Output, and "reasoning"
+// uses Summary.
+type responsesItem struct {
+	Type      string             `json:"type"`
+	Role      string             `json:"role,omitempty"`
+	Content   []responsesContent `json:"content,omitempty"`
+	CallID    string             `json:"call_id,omitempty"`
+	Name      string             `json:"name,omitempty"`
+	Arguments string             `json:"arguments,omitempty"`
+	Output    string             `json:"output,omitempty"`
+	Summary   []responsesContent `json:"summary,omitempty"`
+}
+
+type responsesContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Refusal  string `json:"refusal,omitempty"`
+}
+
+// responsesTool is a function tool, or a built-in tool when Type is not
+// "function". Strict is always sent for function tools, as the Responses API
+// otherwise assumes strict schemas, which converted tools are not.
+type responsesTool struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+	Strict      bool   `json:"strict"`
+}
+
+// MarshalJSON sends a built-in tool as its type alone, since the function
+// fields do not apply to it.
+func (t responsesTool) MarshalJSON() ([]byte, error) {
+	if t.Type != "function" {
+		return json.Marshal(struct {
+			Type string `json:"type"`
+		}{t.Type})
+	}
+	type plain responsesTool
+	return json.Marshal(plain(t))
+}
+
+// responsesFormat selects the output format. Name, Schema and Strict apply to
+// the "json_schema" type only.
+type responsesFormat struct {
+	Format struct {
+		Type   string     `json:"type"`
+		Name   string     `json:"name,omitempty"`
+		Schema jsonSchema `json:"schema,omitempty"`
+		Strict bool       `json:"strict,omitempty"`
+	} `json:"format"`
+}
+
+type responsesResponse struct {
+	ID     string          `json:"id"`
+	Status string          `json:"status"`
+	Output []responsesItem `json:"output"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details,omitempty"`
+	Usage *struct {
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		TotalTokens        int `json:"total_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
+	} `json:"usage,omitempty"`
+}
+
+func (o *OpenAIResponsesModel) toResponsesRequest(req *model.LLMRequest) (*responsesRequest, error) {
+	respReq := &responsesRequest{Model: o.ModelName}
+	for _, content := range req.Contents {
+		items, err := toResponsesItems(content)
+		if err != nil {
+			return nil, err
+		}
+		respReq.Input = append(respReq.Input, items...)
+	}
+
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+	respReq.Temperature = cfg.Temperature
+	respReq.TopP = cfg.TopP
+	respReq.MaxOutputTokens = int(cfg.MaxOutputTokens)
+	if cfg.SystemInstruction != nil {
+		respReq.Instructions = extractTextFromContent(cfg.SystemInstruction, defaultTextSeparator)
+	}
+	if cfg.ResponseSchema != nil {
+		schema, err := convertSchema(cfg.ResponseSchema, schemaOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert response schema: %w", err)
+		}
+		respReq.Text = &responsesFormat{}
+		respReq.Text.Format.Type = "json_schema"
+		respReq.Text.Format.Name = "response"
+		respReq.Text.Format.Schema = schema
+	} else if cfg.ResponseMIMEType == "application/json" {
+		respReq.Text = &responsesFormat{}
+		respReq.Text.Format.Type = "json_object"
+	}
+
+	tools, err := convertTools(cfg.Tools, schemaOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		respReq.Tools = append(respReq.Tools, responsesTool{
+			Type:        "function",
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+			Strict:      tool.Function.Strict,
+		})
+	}
+	for _, tool := range o.BuiltinTools {
+		respReq.Tools = append(respReq.Tools, responsesTool{Type: string(tool)})
+	}
+	return respReq, nil
+}
+
+// toResponsesItems converts content into input items. Text and images become
+// a message, while function calls and responses become items of their own,
+// keeping the order of the parts.
+func toResponsesItems(content *genai.Content) ([]responsesItem, error) {
+	role := convertRoleToOpenAI(content.Role)
+	if role == openai.ChatMessageRoleTool {
+		// Tool results travel as function_call_output items, not messages
+		role = openai.ChatMessageRoleUser
+	}
+	textType := "input_text"
+	if role == openai.ChatMessageRoleAssistant {
+		textType = "output_text"
+	}
+
+	var items []responsesItem
+	var message []responsesContent
+	flush := func() {
+		if len(message) > 0 {
+			items = append(items, responsesItem{Type: "message", Role: role, Content: message})
+			message = nil
+		}
+	}
+	for _, part := range content.Parts {
+		switch {
+		case part.Thought:
+			// Reasoning summaries cannot be replayed as input
+		case hasText(part):
+			message = append(message, responsesContent{Type: textType, Text: part.Text})
+		case part.InlineData != nil:
+			if err := videoPartError(part); err != nil {
+				return nil, err
+			}
+			message = append(message, responsesContent{
+				Type:     "input_image",
+				ImageURL: fmt.Sprintf("data:%s;base64,%s", part.InlineData.MIMEType, base64.StdEncoding.EncodeToString(part.InlineData.Data)),
+			})
+		case part.FunctionCall != nil:
+			flush()
+			args, err := marshalJSON(part.FunctionCall.Args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal function arguments: %w", err)
+			}
+			items = append(items, responsesItem{
+				Type:      "function_call",
+				CallID:    part.FunctionCall.ID,
+				Name:      part.FunctionCall.Name,
+				Arguments: string(args),
+			})
+		case part.FunctionResponse != nil:
+			flush()
+			output, err := toolResponseContent(part.FunctionResponse.Response, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, responsesItem{
+				Type:   "function_call_output",
+				CallID: part.FunctionResponse.ID,
+				Output: output,
+			})
+		}
+	}
+	flush()
+	return items, nil
+}
+
+func convertResponsesResponse(resp *responsesResponse) *model.LLMResponse {
+	content := &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{}}
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "reasoning":
+			for _, summary := range item.Summary {
+				content.Parts = append(content.Parts, &genai.Part{Text: summary.Text, Thought: true})
+			}
+		case "message":
+			for _, c := range item.Content {
+				switch c.Type {
+				case "output_text":
+					content.Parts = append(content.Parts, &genai.Part{Text: c.Text})
+				case "refusal":
+					content.Parts = append(content.Parts, &genai.Part{Text: c.Refusal})
+				}
+			}
+		case "function_call":
+			content.Parts = append(content.Parts, &genai.Part{
+				FunctionCall: &genai.FunctionCall{
+					ID:   item.CallID,
+					Name: item.Name,
+					Args: parseJSONArgs(item.Arguments),
+				},
+			})
+		}
+	}
+
+	llmResp := &model.LLMResponse{
+		Content:      content,
+		FinishReason: genai.FinishReasonStop,
+		TurnComplete: true,
+	}
+	if resp.Status == "incomplete" && resp.IncompleteDetails != nil {
+		switch resp.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			llmResp.FinishReason = genai.FinishReasonMaxTokens
+		case "content_filter":
+			llmResp.FinishReason = genai.FinishReasonSafety
+		default:
+			llmResp.FinishReason = genai.FinishReasonOther
+		}
+	}
+	if resp.Usage != nil {
+		llmResp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        int32(resp.Usage.InputTokens),
+			CandidatesTokenCount:    int32(resp.Usage.OutputTokens),
+			TotalTokenCount:         int32(resp.Usage.TotalTokens),
+			CachedContentTokenCount: int32(resp.Usage.InputTokensDetails.CachedTokens),
+			ThoughtsTokenCount:      int32(resp.Usage.OutputTokensDetails.ReasoningTokens),
+		}
+	}
+	return llmResp
+}
+
+// httpResponsesClient sends Responses API requests over HTTP.
+type httpResponsesClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient openai.HTTPDoer
+}
+
+func (c *httpResponsesClient) CreateResponse(ctx context.Context, req *responsesRequest) (*responsesResponse, error) {
+	httpResp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp responsesResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *httpResponsesClient) StreamResponse(ctx context.Context, req *responsesRequest) (responsesStream, error) {
+	httpResp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &sseResponsesStream{body: httpResp.Body, reader: bufio.NewReader(httpResp.Body)}, nil
+}
+
+// post sends req to the responses endpoint, turning error statuses into
+// errors.
+func (c *httpResponsesClient) post(ctx context.Context, req *responsesRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/responses", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if req.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < http.StatusBadRequest {
+		return httpResp, nil
+	}
+
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var errResp openai.ErrorResponse
+	if err := json.Unmarshal(data, &errResp); err != nil || errResp.Error == nil {
+		return nil, fmt.Errorf("responses API error, status %s: %s", httpResp.Status, data)
+	}
+	errResp.Error.HTTPStatus = httpResp.Status
+	errResp.Error.HTTPStatusCode = httpResp.StatusCode
+	return nil, errResp.Error
+}
+
+// sseResponsesStream decodes the data lines of a server-sent event stream
+// into events, skipping event names, comments and blank lines.
+type sseResponsesStream struct {
+	body   io.Closer
+	reader *bufio.Reader
+}
+
+func (s *sseResponsesStream) Recv() (responsesEvent, error) {
+	for {
+		line, err := s.reader.ReadString('\n')
+		if data, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "data:"); ok {
+			var event responsesEvent
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+				return responsesEvent{}, fmt.Errorf("failed to decode stream event: %w", err)
+			}
+			return event, nil
+		}
+		if err != nil {
+			return responsesEvent{}, err
+		}
+	}
+}
+
+func (s *sseResponsesStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,399 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// fakeResponsesClient records the last request and answers with resp, or
+// with events when streaming.
+type fakeResponsesClient struct {
+	req    *responsesRequest
+	resp   *responsesResponse
+	events []responsesEvent
+}
+
+func (c *fakeResponsesClient) CreateResponse(_ context.Context, req *responsesRequest) (*responsesResponse, error) {
+	c.req = req
+	return c.resp, nil
+}
+
+func (c *fakeResponsesClient) StreamResponse(_ context.Context, req *responsesRequest) (responsesStream, error) {
+	c.req = req
+	return &fakeResponsesStream{events: c.events}, nil
+}
+
+// fakeResponsesStream returns events in order, then io.EOF.
+type fakeResponsesStream struct {
+	events []responsesEvent
+}
+
+func (s *fakeResponsesStream) Recv() (responsesEvent, error) {
+	if len(s.events) == 0 {
+		return responsesEvent{}, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func (s *fakeResponsesStream) Close() error {
+	return nil
+}
+
+func generateOnce(t *testing.T, m model.LLM, req *model.LLMRequest) *model.LLMResponse {
+	t.Helper()
+	var got *model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = resp
+	}
+	return got
+}
+
+func TestOpenAIResponsesModel_Text(t *testing.T) {
+	client := &fakeResponsesClient{resp: &responsesResponse{
+		Status: "completed",
+		Output: []responsesItem{
+			{Type: "reasoning", Summary: []responsesContent{{Type: "summary_text", Text: "Greeting back."}}},
+			{Type: "message", Role: "assistant", Content: []responsesContent{{Type: "output_text", Text: "Hi there!"}}},
+		},
+	}}
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1", client: client}
+
+	got := generateOnce(t, m, &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: "Be brief."}}},
+		},
+	})
+
+	wantReq := &responsesRequest{
+		Model:        "gpt-5.1",
+		Instructions: "Be brief.",
+		Input: []responsesItem{
+			{Type: "message", Role: "user", Content: []responsesContent{{Type: "input_text", Text: "Hello"}}},
+		},
+	}
+	if diff := cmp.Diff(wantReq, client.req); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+
+	wantParts := []*genai.Part{
+		{Text: "Greeting back.", Thought: true},
+		{Text: "Hi there!"},
+	}
+	if diff := cmp.Diff(wantParts, got.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+	if got.FinishReason != genai.FinishReasonStop || !got.TurnComplete {
+		t.Errorf("FinishReason = %q, TurnComplete = %v", got.FinishReason, got.TurnComplete)
+	}
+}
+
+func TestOpenAIResponsesModel_ToolCall(t *testing.T) {
+	client := &fakeResponsesClient{resp: &responsesResponse{
+		Status: "completed",
+		Output: []responsesItem{
+			{Type: "function_call", CallID: "call_2", Name: "weather", Arguments: `{"city":"Rome"}`},
+		},
+	}}
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1", client: client}
+
+	got := generateOnce(t, m, &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Weather in Paris, then Rome?"}}},
+			{Role: "model", Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "weather", Args: map[string]any{"city": "Paris"}}},
+			}},
+			{Role: "user", Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "weather", Response: map[string]any{"sky": "sunny"}}},
+			}},
+		},
+		Config: &genai.GenerateContentConfig{
+			Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:                 "weather",
+				Description:          "Returns the weather in a city",
+				ParametersJsonSchema: map[string]any{"type": "object"},
+			}}}},
+		},
+	})
+
+	wantReq := &responsesRequest{
+		Model: "gpt-5.1",
+		Input: []responsesItem{
+			{Type: "message", Role: "user", Content: []responsesContent{{Type: "input_text", Text: "Weather in Paris, then Rome?"}}},
+			{Type: "function_call", CallID: "call_1", Name: "weather", Arguments: `{"city":"Paris"}`},
+			{Type: "function_call_output", CallID: "call_1", Output: `{"sky":"sunny"}`},
+		},
+		Tools: []responsesTool{{
+			Type:        "function",
+			Name:        "weather",
+			Description: "Returns the weather in a city",
+			Parameters:  map[string]any{"type": "object"},
+		}},
+	}
+	if diff := cmp.Diff(wantReq, client.req); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+
+	wantParts := []*genai.Part{
+		{FunctionCall: &genai.FunctionCall{ID: "call_2", Name: "weather", Args: map[string]any{"city": "Rome"}}},
+	}
+	if diff := cmp.Diff(wantParts, got.Content.Parts); diff != "" {
+		t.Errorf("parts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToResponsesRequest_ToolResults(t *testing.T) {
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1"}
+	req, err := m.toResponsesRequest(&model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{
+				{FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "weather", Response: map[string]any{"result": "sunny"}}},
+				{FunctionResponse: &genai.FunctionResponse{ID: "call_2", Name: "weather", Response: map[string]any{"result": 21}}},
+			}},
+		},
+		Config: &genai.GenerateContentConfig{
+			Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:                 "weather",
+				ParametersJsonSchema: map[string]any{"type": "object"},
+			}}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toResponsesRequest() error = %v", err)
+	}
+
+	var outputs []string
+	for _, item := range req.Input {
+		outputs = append(outputs, item.Output)
+	}
+	if diff := cmp.Diff([]string{"sunny", "21"}, outputs); diff != "" {
+		t.Errorf("outputs mismatch (-want +got):\n%s", diff)
+	}
+
+	body, err := json.Marshal(req.Tools[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tool map[string]any
+	if err := json.Unmarshal(body, &tool); err != nil {
+		t.Fatal(err)
+	}
+	if strict, ok := tool["strict"]; !ok || strict != false {
+		t.Errorf("tool strict = %v (present %v), want false", strict, ok)
+	}
+}
+
+func TestNewOpenAIResponsesModel(t *testing.T) {
+	s, _ := newFakeServer(t)
+	s.handle("/responses", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responsesResponse{
+			Status: "incomplete",
+			IncompleteDetails: &struct {
+				Reason string `json:"reason"`
+			}{Reason: "max_output_tokens"},
+			Output: []responsesItem{
+				{Type: "message", Role: "assistant", Content: []responsesContent{{Type: "output_text", Text: "Once upon"}}},
+			},
+		})
+	})
+
+	m := NewOpenAIResponsesModel("gpt-5.1", "secret", s.url+"/")
+	got := generateOnce(t, m, &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Tell a story"}}}},
+	})
+
+	if reqs := s.requestsTo("/responses"); len(reqs) != 1 {
+		t.Errorf("got %d requests to /responses, want 1", len(reqs))
+	} else if auth := reqs[0].header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+	if got.FinishReason != genai.FinishReasonMaxTokens {
+		t.Errorf("FinishReason = %q, want %q", got.FinishReason, genai.FinishReasonMaxTokens)
+	}
+}
+
+func TestOpenAIResponsesModel_Stream(t *testing.T) {
+	client := &fakeResponsesClient{events: []responsesEvent{
+		{Type: "response.created"},
+		{Type: "response.reasoning_summary_text.delta", Delta: "Greeting back."},
+		{Type: "response.output_text.delta", Delta: "Hi "},
+		{Type: "response.output_text.delta", Delta: "there!"},
+		{Type: "response.completed", Response: &responsesResponse{
+			Status: "completed",
+			Output: []responsesItem{
+				{Type: "reasoning", Summary: []responsesContent{{Type: "summary_text", Text: "Greeting back."}}},
+				{Type: "message", Role: "assistant", Content: []responsesContent{{Type: "output_text", Text: "Hi there!"}}},
+			},
+		}},
+	}}
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1", client: client}
+
+	var resps []*model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+	}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resps = append(resps, resp)
+	}
+
+	if !client.req.Stream {
+		t.Error("request Stream = false, want true")
+	}
+	want := []*model.LLMResponse{
+		{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: "Greeting back.", Thought: true}}}, Partial: true},
+		{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: "Hi "}}}, Partial: true},
+		{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: "there!"}}}, Partial: true},
+		{
+			Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+				{Text: "Greeting back.", Thought: true},
+				{Text: "Hi there!"},
+			}},
+			FinishReason: genai.FinishReasonStop,
+			TurnComplete: true,
+		},
+	}
+	if diff := cmp.Diff(want, resps); diff != "" {
+		t.Errorf("responses mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenAIResponsesModel_StreamErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []responsesEvent
+		want   string
+	}{
+		{
+			name:   "error event",
+			events: []responsesEvent{{Type: "error", Code: "rate_limit_exceeded", Message: "slow down"}},
+			want:   "slow down",
+		},
+		{
+			name: "failed response",
+			events: []responsesEvent{{Type: "response.failed", Response: &responsesResponse{
+				Status: "failed",
+				Error: &struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				}{Code: "server_error", Message: "something broke"},
+			}}},
+			want: "something broke",
+		},
+		{
+			name:   "cut off",
+			events: []responsesEvent{{Type: "response.output_text.delta", Delta: "Hi"}},
+			want:   "responses stream ended before the response completed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIResponsesModel{ModelName: "gpt-5.1", client: &fakeResponsesClient{events: tt.events}}
+			var gotErr error
+			for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+			}, true) {
+				if err != nil {
+					gotErr = err
+				}
+			}
+			if gotErr == nil || !strings.Contains(gotErr.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", gotErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOpenAIResponsesModel_Stream(t *testing.T) {
+	s, _ := newFakeServer(t)
+	s.handle("/responses", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []responsesEvent{
+			{Type: "response.output_text.delta", Delta: "Once "},
+			{Type: "response.output_text.delta", Delta: "upon"},
+			{Type: "response.completed", Response: &responsesResponse{
+				Status: "completed",
+				Output: []responsesItem{
+					{Type: "message", Role: "assistant", Content: []responsesContent{{Type: "output_text", Text: "Once upon"}}},
+				},
+			}},
+		} {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+	})
+
+	m := NewOpenAIResponsesModel("gpt-5.1", "secret", s.url)
+	var text []string
+	var final *model.LLMResponse
+	for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Tell a story"}}}},
+	}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if resp.Partial {
+			text = append(text, resp.Content.Parts[0].Text)
+		} else {
+			final = resp
+		}
+	}
+
+	if diff := cmp.Diff([]string{"Once ", "upon"}, text); diff != "" {
+		t.Errorf("partial text mismatch (-want +got):\n%s", diff)
+	}
+	if final == nil || final.Content.Parts[0].Text != "Once upon" {
+		t.Errorf("final = %+v, want Once upon", final)
+	}
+	reqs := s.requestsTo("/responses")
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests to /responses, want 1", len(reqs))
+	}
+	var body map[string]any
+	reqs[0].decode(t, &body)
+	if body["stream"] != true {
+		t.Errorf("stream = %v, want true", body["stream"])
+	}
+}
+
+func TestToResponsesRequest_ResponseSchema(t *testing.T) {
+	m := &OpenAIResponsesModel{ModelName: "gpt-5.1"}
+	req, err := m.toResponsesRequest(&model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Describe a cat"}}}},
+		Config: &genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema: &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{"name": {Type: genai.TypeString}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toResponsesRequest() error = %v", err)
+	}
+
+	data, err := json.Marshal(req.Text)
+	if err != nil {
+		t.Fatalf("failed to marshal text: %v", err)
+	}
+	want := `{"format":{"type":"json_schema","name":"response","schema":{"properties":{"name":{"type":"string"}},"type":"object"}}}`
+	if string(data) != want {
+		t.Errorf("text = %s, want %s", data, want)
+	}
+}
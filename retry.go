@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// streamReconnectDelay is the wait before the first stream reconnect. It
+// doubles with every further attempt.
+var streamReconnectDelay = 200 * time.Millisecond
+
+// isRetryableError reports whether err is transient, so that reissuing the
+// same request may succeed: rate limiting, server errors and dropped
+// connections. Cancellation and deadlines are never retryable.
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return retryableStatus(reqErr.HTTPStatusCode)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryableStatus reports whether an HTTP status signals a transient failure.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// streamWithReconnects opens a stream with open and reads it into yield. If
+// the stream fails with a retryable error before any response was yielded,
+// the request is reissued, up to StreamReconnects times. Once a response has
+// been delivered, errors are passed on, so output is never replayed.
+func (o *OpenAIModel) streamWithReconnects(ctx context.Context, open func() (chatCompletionStream, error), yield func(*model.LLMResponse, error) bool) {
+	delay := streamReconnectDelay
+	for attempt := 0; ; attempt++ {
+		canRetry := func(err error) bool {
+			return attempt < o.StreamReconnects && isRetryableError(err)
+		}
+
+		stream, err := open()
+		var retryErr error
+		if err != nil {
+			if !canRetry(err) {
+				yield(nil, err)
+				return
+			}
+			retryErr = err
+		} else {
+			delivered := false
+			o.readStream(ctx, stream, func(resp *model.LLMResponse, err error) bool {
+				if err != nil && !delivered && canRetry(err) {
+					retryErr = err
+					return false
+				}
+				delivered = true
+				return yield(resp, err)
+			})
+			if retryErr == nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			yield(nil, retryErr)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
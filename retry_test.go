@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited", err: &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "server error", err: &openai.RequestError{HTTPStatusCode: http.StatusBadGateway}, want: true},
+		{name: "bad request", err: &openai.APIError{HTTPStatusCode: http.StatusBadRequest}, want: false},
+		{name: "dropped connection", err: fmt.Errorf("stream: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: false},
+		{name: "other", err: errors.New("boom"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateContent_StreamReconnects(t *testing.T) {
+	delay := streamReconnectDelay
+	streamReconnectDelay = time.Millisecond
+	t.Cleanup(func() { streamReconnectDelay = delay })
+
+	tests := []struct {
+		name         string
+		reconnects   int
+		failures     int
+		before       []string
+		wantErr      bool
+		wantText     string
+		wantRequests int
+	}{
+		{name: "disabled", reconnects: 0, failures: 1, wantErr: true, wantRequests: 1},
+		{name: "server error before first token", reconnects: 2, failures: 2, wantText: "Hello", wantRequests: 3},
+		{name: "reconnects exhausted", reconnects: 1, failures: 2, wantErr: true, wantRequests: 2},
+		{name: "dropped after first token", reconnects: 2, failures: 1, before: []string{"Hel"}, wantErr: true, wantText: "Hel", wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A failing stream sends before and then drops the connection, or
+			// answers 503 if before is empty.
+			s, m := newFakeServer(t)
+			s.chunks = []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, openai.FinishReasonStop),
+			}
+			s.failures = tt.failures
+			for _, text := range tt.before {
+				s.failChunks = append(s.failChunks, deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: text}, ""))
+			}
+			m.StreamReconnects = tt.reconnects
+
+			var text string
+			var gotErr error
+			for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hi"}}}},
+			}, true) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				if resp.Partial {
+					text += resp.Content.Parts[0].Text
+				}
+			}
+
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("GenerateContent() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if text != tt.wantText {
+				t.Errorf("streamed text = %q, want %q", text, tt.wantText)
+			}
+			if got := len(s.requestsTo("/chat/completions")); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
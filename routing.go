@@ -0,0 +1,25 @@
+package openai
+
+import "google.golang.org/genai"
+
+// openRouterRoutingHints maps a genai routing config onto OpenRouter's routing
+// parameters. A cost preference sorts providers by price, and manual mode
+// pins the named model through the models list. Other preferences carry no
+// hint and leave OpenRouter's default load balancing in place.
+func openRouterRoutingHints(routing *genai.GenerationConfigRoutingConfig) map[string]any {
+	if routing == nil {
+		return nil
+	}
+
+	hints := map[string]any{}
+	if routing.AutoMode != nil && routing.AutoMode.ModelRoutingPreference == "PRIORITIZE_COST" {
+		hints["provider"] = map[string]any{"sort": "price"}
+	}
+	if routing.ManualMode != nil && routing.ManualMode.ModelName != "" {
+		hints["models"] = []string{routing.ManualMode.ModelName}
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	return hints
+}
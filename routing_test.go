@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestGenerateContent_OpenRouterRoutingHints(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		routing *genai.GenerationConfigRoutingConfig
+		want    map[string]any
+	}{
+		{
+			name:    "cost preference",
+			profile: ProfileOpenRouter,
+			routing: &genai.GenerationConfigRoutingConfig{
+				AutoMode: &genai.GenerationConfigRoutingConfigAutoRoutingMode{ModelRoutingPreference: "PRIORITIZE_COST"},
+			},
+			want: map[string]any{"provider": map[string]any{"sort": "price"}},
+		},
+		{
+			name:    "manual model",
+			profile: ProfileOpenRouter,
+			routing: &genai.GenerationConfigRoutingConfig{
+				ManualMode: &genai.GenerationConfigRoutingConfigManualRoutingMode{ModelName: "anthropic/claude-sonnet-4"},
+			},
+			want: map[string]any{"models": []any{"anthropic/claude-sonnet-4"}},
+		},
+		{
+			name:    "default profile ignores routing",
+			profile: ProfileOpenAI,
+			routing: &genai.GenerationConfigRoutingConfig{
+				AutoMode: &genai.GenerationConfigRoutingConfigAutoRoutingMode{ModelRoutingPreference: "PRIORITIZE_COST"},
+			},
+			want: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			m.ModelName = "openai/gpt-4o"
+			m.Profile = tt.profile
+			generate(t, context.Background(), m, &genai.GenerateContentConfig{RoutingConfig: tt.routing})
+			var body map[string]any
+			s.lastBody(t, &body)
+
+			if body["model"] != "openai/gpt-4o" {
+				t.Errorf("model = %v, want openai/gpt-4o", body["model"])
+			}
+			got := map[string]any{}
+			for _, key := range []string{"provider", "models"} {
+				if v, ok := body[key]; ok {
+					got[key] = v
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("routing hints mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
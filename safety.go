@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// SafetyRatingsMetadataKey is the LLMResponse.CustomMetadata key under which the
+// content filter results of the first choice are stored as
+// []*genai.SafetyRating. Azure OpenAI reports them; when several candidates
+// are returned, each candidate also carries its own SafetyRatings.
+const SafetyRatingsMetadataKey = "safety_ratings"
+
+// convertContentFilterResults converts Azure content filter results into
+// genai safety ratings. Categories without a genai counterpart map to the
+// closest one: self-harm and violence to dangerous content, profanity to
+// harassment. Categories the service did not report are omitted.
+func convertContentFilterResults(results openai.ContentFilterResults) []*genai.SafetyRating {
+	var ratings []*genai.SafetyRating
+	addSeverity := func(category genai.HarmCategory, filtered bool, severity string) {
+		if !filtered && severity == "" {
+			return
+		}
+		ratings = append(ratings, &genai.SafetyRating{
+			Category: category,
+			Blocked:  filtered,
+			Severity: convertSeverity(severity),
+		})
+	}
+	addDetection := func(category genai.HarmCategory, filtered, detected bool) {
+		if !filtered && !detected {
+			return
+		}
+		probability := genai.HarmProbabilityNegligible
+		if detected {
+			probability = genai.HarmProbabilityHigh
+		}
+		ratings = append(ratings, &genai.SafetyRating{
+			Category:    category,
+			Blocked:     filtered,
+			Probability: probability,
+		})
+	}
+
+	addSeverity(genai.HarmCategoryHateSpeech, results.Hate.Filtered, results.Hate.Severity)
+	addSeverity(genai.HarmCategoryDangerousContent, results.SelfHarm.Filtered, results.SelfHarm.Severity)
+	addSeverity(genai.HarmCategorySexuallyExplicit, results.Sexual.Filtered, results.Sexual.Severity)
+	addSeverity(genai.HarmCategoryDangerousContent, results.Violence.Filtered, results.Violence.Severity)
+	addDetection(genai.HarmCategoryJailbreak, results.JailBreak.Filtered, results.JailBreak.Detected)
+	addDetection(genai.HarmCategoryHarassment, results.Profanity.Filtered, results.Profanity.Detected)
+	return ratings
+}
+
+func convertSeverity(severity string) genai.HarmSeverity {
+	switch strings.ToLower(severity) {
+	case "safe":
+		return genai.HarmSeverityNegligible
+	case "low":
+		return genai.HarmSeverityLow
+	case "medium":
+		return genai.HarmSeverityMedium
+	case "high":
+		return genai.HarmSeverityHigh
+	default:
+		return genai.HarmSeverityUnspecified
+	}
+}
+
+// SafetyError reports a streamed response that was cut off by the content
+// filter. Response holds the content received before the filter triggered.
+type SafetyError struct {
+	Response *model.LLMResponse
+}
+
+func (e *SafetyError) Error() string {
+	return "response blocked by content filter"
+}
+
+// safetyError returns a SafetyError for a candidate the content filter
+// stopped, completing the candidate with what it aggregated so far.
+func (o *OpenAIModel) safetyError(candidate *streamCandidate) *SafetyError {
+	o.finishStreamCandidate(candidate)
+	resp := &model.LLMResponse{
+		Content:      candidate.content,
+		FinishReason: genai.FinishReasonSafety,
+		TurnComplete: true,
+	}
+	if len(candidate.safetyRatings) > 0 {
+		setCustomMetadata(resp, SafetyRatingsMetadataKey, candidate.safetyRatings)
+	}
+	return &SafetyError{Response: resp}
+}
+
+// setCustomMetadata stores value under key in resp.CustomMetadata, creating
+// the map if needed.
+func setCustomMetadata(resp *model.LLMResponse, key string, value any) {
+	if resp.CustomMetadata == nil {
+		resp.CustomMetadata = map[string]any{}
+	}
+	resp.CustomMetadata[key] = value
+}
@@ -0,0 +1,125 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+var filteredResults = openai.ContentFilterResults{
+	Hate:      openai.Hate{Filtered: false, Severity: "safe"},
+	Violence:  openai.Violence{Filtered: true, Severity: "high"},
+	JailBreak: openai.JailBreak{Filtered: false, Detected: false},
+}
+
+var wantFilteredRatings = []*genai.SafetyRating{
+	{Category: genai.HarmCategoryHateSpeech, Severity: genai.HarmSeverityNegligible},
+	{Category: genai.HarmCategoryDangerousContent, Blocked: true, Severity: genai.HarmSeverityHigh},
+}
+
+func TestConvertChatCompletionResponse_ContentFilterResults(t *testing.T) {
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{
+			Message:              openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant},
+			FinishReason:         openai.FinishReasonContentFilter,
+			ContentFilterResults: filteredResults,
+		}},
+	}
+
+	got, err := convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if got.FinishReason != genai.FinishReasonSafety {
+		t.Errorf("FinishReason = %q, want %q", got.FinishReason, genai.FinishReasonSafety)
+	}
+	if diff := cmp.Diff(wantFilteredRatings, got.CustomMetadata[SafetyRatingsMetadataKey]); diff != "" {
+		t.Errorf("safety ratings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertChatCompletionResponse_CandidateSafetyRatings(t *testing.T) {
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{Index: 0, Message: openai.ChatCompletionMessage{Content: "fine"}, FinishReason: openai.FinishReasonStop},
+			{Index: 1, FinishReason: openai.FinishReasonContentFilter, ContentFilterResults: filteredResults},
+		},
+	}
+
+	got, err := convertChatCompletionResponse(resp, convertFinishReason)
+	if err != nil {
+		t.Fatalf("convertChatCompletionResponse() error = %v", err)
+	}
+	if _, ok := got.CustomMetadata[SafetyRatingsMetadataKey]; ok {
+		t.Error("unfiltered first choice reported safety ratings")
+	}
+	candidates := got.CustomMetadata[CandidatesMetadataKey].([]*genai.Candidate)
+	if diff := cmp.Diff(wantFilteredRatings, candidates[1].SafetyRatings); diff != "" {
+		t.Errorf("candidate safety ratings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_ContentFilterResults(t *testing.T) {
+	filtered := deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonContentFilter)
+	filtered.Choices[0].ContentFilterResults = filteredResults
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Here is how"}, ""),
+		filtered,
+	}}
+
+	resps := collectStream(t, &OpenAIModel{}, stream)
+	final := resps[len(resps)-1]
+	if final.FinishReason != genai.FinishReasonSafety {
+		t.Errorf("FinishReason = %q, want %q", final.FinishReason, genai.FinishReasonSafety)
+	}
+	if diff := cmp.Diff(wantFilteredRatings, final.CustomMetadata[SafetyRatingsMetadataKey]); diff != "" {
+		t.Errorf("safety ratings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadStream_AbortOnContentFilter(t *testing.T) {
+	filtered := deltaChunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonContentFilter)
+	filtered.Choices[0].ContentFilterResults = filteredResults
+	stream := &fakeStream{chunks: []openai.ChatCompletionStreamResponse{
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Here is how"}, ""),
+		filtered,
+		deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: " to"}, ""),
+	}}
+
+	m := &OpenAIModel{AbortOnContentFilter: true}
+	var partials int
+	var gotErr error
+	m.readStream(context.Background(), stream, func(resp *model.LLMResponse, err error) bool {
+		if err != nil {
+			gotErr = err
+			return false
+		}
+		partials++
+		return true
+	})
+
+	var safetyErr *SafetyError
+	if !errors.As(gotErr, &safetyErr) {
+		t.Fatalf("readStream() error = %v, want SafetyError", gotErr)
+	}
+	if partials != 1 {
+		t.Errorf("got %d responses before the error, want 1", partials)
+	}
+	if !stream.closed {
+		t.Error("stream was not closed")
+	}
+	want := &model.LLMResponse{
+		Content:        &genai.Content{Role: "model", Parts: []*genai.Part{{Text: "Here is how"}}},
+		FinishReason:   genai.FinishReasonSafety,
+		TurnComplete:   true,
+		CustomMetadata: map[string]any{SafetyRatingsMetadataKey: wantFilteredRatings},
+	}
+	if diff := cmp.Diff(want, safetyErr.Response); diff != "" {
+		t.Errorf("Response mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,360 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"google.golang.org/genai"
+)
+
+// NullableStyle selects how a nullable schema is expressed in JSON schema.
+type NullableStyle int
+
+const (
+	// NullableTypeArray lists "null" alongside the type, as in
+	// "type": ["string", "null"]. It is the default.
+	NullableTypeArray NullableStyle = iota
+	// NullableAnyOf adds {"type": "null"} as an anyOf alternative, which some
+	// strict structured output validators require.
+	NullableAnyOf
+)
+
+// ResponseSchemaFormat selects how a response schema is sent.
+type ResponseSchemaFormat int
+
+const (
+	// ResponseSchemaStrict sends the schema as a strict json_schema response
+	// format, so that responses always match it. Optional properties become
+	// nullable, since strict mode requires every property. Schemas using
+	// features strict mode does not support, such as minLength, are sent
+	// non-strict instead. It is the default.
+	ResponseSchemaStrict ResponseSchemaFormat = iota
+	// ResponseSchemaLoose sends the schema as a non-strict json_schema
+	// response format, as written.
+	ResponseSchemaLoose
+	// ResponseSchemaJSONObject drops the schema and asks for JSON through the
+	// json_object response format, for backends without json_schema support.
+	ResponseSchemaJSONObject
+)
+
+// schemaOptions controls how convertSchema renders a schema.
+type schemaOptions struct {
+	nullable NullableStyle
+	// strict marks tool definitions strict, running their parameters through
+	// strictJSONSchema.
+	strict bool
+	// lenientTools skips nil function declarations and unsupported built-in
+	// tools instead of failing.
+	lenientTools bool
+}
+
+// schemaOptions returns the schema conversion settings of the model.
+func (o *OpenAIModel) schemaOptions() schemaOptions {
+	return schemaOptions{nullable: o.NullableStyle, strict: o.StrictTools, lenientTools: o.LenientTools}
+}
+
+// convertSchema converts a genai schema into the JSON schema map OpenAI
+// expects for tool parameters.
+func convertSchema(schema *genai.Schema, opts schemaOptions) (map[string]any, error) {
+	if schema == nil {
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}, nil
+	}
+
+	result := make(map[string]any)
+
+	// Convert type
+	if schema.Type != "" && schema.Type != genai.TypeUnspecified {
+		result["type"] = convertSchemaType(schema.Type)
+	}
+
+	// Add description
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+
+	// Convert properties recursively
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]any)
+		for propName, propSchema := range schema.Properties {
+			convertedProp, err := convertSchema(propSchema, opts)
+			if err != nil {
+				return nil, err
+			}
+			properties[propName] = convertedProp
+		}
+		result["properties"] = properties
+	}
+
+	// Add required fields
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+
+	// Convert array items
+	if schema.Items != nil {
+		items, err := convertSchema(schema.Items, opts)
+		if err != nil {
+			return nil, err
+		}
+		result["items"] = items
+	}
+
+	// Add enum if present
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+
+	// Add validation hints. Bounds are pointers so that an explicit zero is
+	// kept while an unset bound is omitted.
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+	if schema.Minimum != nil {
+		result["minimum"] = *schema.Minimum
+	}
+	if schema.Maximum != nil {
+		result["maximum"] = *schema.Maximum
+	}
+	if schema.MinItems != nil {
+		result["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	if schema.MinLength != nil {
+		result["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.MinProperties != nil {
+		result["minProperties"] = *schema.MinProperties
+	}
+	if schema.MaxProperties != nil {
+		result["maxProperties"] = *schema.MaxProperties
+	}
+
+	// Convert union alternatives
+	if len(schema.AnyOf) > 0 {
+		anyOf := make([]any, 0, len(schema.AnyOf))
+		for _, alt := range schema.AnyOf {
+			converted, err := convertSchema(alt, opts)
+			if err != nil {
+				return nil, err
+			}
+			anyOf = append(anyOf, converted)
+		}
+		result["anyOf"] = anyOf
+	}
+
+	if schema.Nullable != nil && *schema.Nullable {
+		result = makeNullable(result, opts.nullable)
+	}
+
+	return result, nil
+}
+
+// makeNullable extends a converted schema to also accept null.
+func makeNullable(result map[string]any, style NullableStyle) map[string]any {
+	nullSchema := map[string]any{"type": "null"}
+	if anyOf, ok := result["anyOf"].([]any); ok {
+		result["anyOf"] = append(anyOf, nullSchema)
+		return result
+	}
+
+	if style == NullableAnyOf {
+		nullable := map[string]any{}
+		if description, ok := result["description"]; ok {
+			nullable["description"] = description
+			delete(result, "description")
+		}
+		nullable["anyOf"] = []any{result, nullSchema}
+		return nullable
+	}
+
+	if t, ok := result["type"].(string); ok {
+		result["type"] = []string{t, "null"}
+	}
+	if enum, ok := result["enum"].([]string); ok {
+		values := make([]any, 0, len(enum)+1)
+		for _, v := range enum {
+			values = append(values, v)
+		}
+		result["enum"] = append(values, nil)
+	}
+	return result
+}
+
+func convertSchemaType(t genai.Type) string {
+	switch t {
+	case genai.TypeString:
+		return "string"
+	case genai.TypeNumber:
+		return "number"
+	case genai.TypeInteger:
+		return "integer"
+	case genai.TypeBoolean:
+		return "boolean"
+	case genai.TypeArray:
+		return "array"
+	case genai.TypeObject:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// strictUnsupportedKeywords lists the JSON schema keywords OpenAI Structured
+// Outputs rejects.
+var strictUnsupportedKeywords = []string{
+	"allOf", "not", "if", "then", "else", "dependentRequired", "dependentSchemas",
+	"patternProperties", "unevaluatedProperties", "propertyNames", "minProperties", "maxProperties",
+	"minLength", "maxLength",
+	"unevaluatedItems", "contains", "minContains", "maxContains", "uniqueItems",
+}
+
+// strictJSONSchema rewrites a converted schema in place for OpenAI Structured
+// Outputs: every object forbids additional properties and requires all of its
+// properties, with the ones that were optional made nullable in the given
+// style so the model can still leave them out. It returns an error naming the
+// offending path if the schema uses a feature strict mode does not support.
+func strictJSONSchema(schema map[string]any, style NullableStyle) error {
+	return strictJSONSchemaAt(schema, "#", style)
+}
+
+// schemaChild is a subschema together with its JSON pointer path.
+type schemaChild struct {
+	schema any
+	path   string
+}
+
+func strictJSONSchemaAt(schema map[string]any, path string, style NullableStyle) error {
+	for _, keyword := range strictUnsupportedKeywords {
+		if _, ok := schema[keyword]; ok {
+			return fmt.Errorf("strict schema does not support %q at %s", keyword, path)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if properties != nil || schema["type"] == "object" {
+		if additional, ok := schema["additionalProperties"]; ok && additional != false {
+			return fmt.Errorf("strict schema requires additionalProperties false at %s", path)
+		}
+		wasRequired := map[string]bool{}
+		switch names := schema["required"].(type) {
+		case []string:
+			for _, name := range names {
+				wasRequired[name] = true
+			}
+		case []any:
+			for _, name := range names {
+				if name, ok := name.(string); ok {
+					wasRequired[name] = true
+				}
+			}
+		}
+		required := make([]string, 0, len(properties))
+		for name, prop := range properties {
+			required = append(required, name)
+			if prop, ok := prop.(map[string]any); ok && !wasRequired[name] {
+				properties[name] = nullableProperty(prop, style)
+			}
+		}
+		sort.Strings(required)
+		schema["required"] = required
+		schema["additionalProperties"] = false
+	}
+
+	var children []schemaChild
+	for _, key := range []string{"properties", "$defs", "definitions"} {
+		if m, ok := schema[key].(map[string]any); ok {
+			for name, child := range m {
+				children = append(children, schemaChild{child, path + "/" + key + "/" + name})
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		children = append(children, schemaChild{items, path + "/items"})
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for i, alt := range anyOf {
+			children = append(children, schemaChild{alt, fmt.Sprintf("%s/anyOf/%d", path, i)})
+		}
+	}
+	for _, child := range children {
+		if m, ok := child.schema.(map[string]any); ok {
+			if err := strictJSONSchemaAt(m, child.path, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nullableProperty makes an optional property accept null unless it already
+// does. Properties makeNullable cannot extend in the type array style, such as
+// references or schemas decoded from JSON, get an anyOf alternative instead.
+func nullableProperty(prop map[string]any, style NullableStyle) map[string]any {
+	if acceptsNull(prop) {
+		return prop
+	}
+	if _, ok := prop["type"].(string); !ok {
+		style = NullableAnyOf
+	}
+	if enum, ok := prop["enum"]; ok {
+		if _, ok := enum.([]string); !ok {
+			style = NullableAnyOf
+		}
+	}
+	return makeNullable(prop, style)
+}
+
+// acceptsNull reports whether a converted schema already admits null.
+func acceptsNull(schema map[string]any) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == "null"
+	case []string:
+		return slices.Contains(t, "null")
+	case []any:
+		return slices.Contains(t, any("null"))
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, alt := range anyOf {
+			if alt, ok := alt.(map[string]any); ok && acceptsNull(alt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toJSONSchemaMap converts a JSON schema given in any JSON-serializable form,
+// such as a *jsonschema.Schema, into a freshly allocated generic map.
+func toJSONSchemaMap(schema any) (map[string]any, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("schema is not a JSON object: %w", err)
+	}
+	return m, nil
+}
+
+// jsonSchema adapts a converted schema to the json.Marshaler go-openai expects
+// for json_schema response formats.
+type jsonSchema map[string]any
+
+func (s jsonSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(s))
+}
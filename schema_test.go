@@ -0,0 +1,474 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestConvertSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *genai.Schema
+		want   map[string]any
+	}{
+		{
+			name:   "nil schema",
+			schema: nil,
+			want:   map[string]any{"type": "object", "properties": map[string]any{}},
+		},
+		{
+			name:   "string format and pattern",
+			schema: &genai.Schema{Type: genai.TypeString, Format: "date-time", Pattern: "^[0-9T:-]+$"},
+			want:   map[string]any{"type": "string", "format": "date-time", "pattern": "^[0-9T:-]+$"},
+		},
+		{
+			name:   "numeric bounds",
+			schema: &genai.Schema{Type: genai.TypeNumber, Minimum: genai.Ptr(-1.5), Maximum: genai.Ptr(10.0)},
+			want:   map[string]any{"type": "number", "minimum": -1.5, "maximum": 10.0},
+		},
+		{
+			name:   "zero bounds are kept",
+			schema: &genai.Schema{Type: genai.TypeInteger, Minimum: genai.Ptr(0.0), MinLength: genai.Ptr[int64](0)},
+			want:   map[string]any{"type": "integer", "minimum": 0.0, "minLength": int64(0)},
+		},
+		{
+			name:   "string length",
+			schema: &genai.Schema{Type: genai.TypeString, MinLength: genai.Ptr[int64](1), MaxLength: genai.Ptr[int64](64)},
+			want:   map[string]any{"type": "string", "minLength": int64(1), "maxLength": int64(64)},
+		},
+		{
+			name: "array and object sizes",
+			schema: &genai.Schema{
+				Type:     genai.TypeArray,
+				Items:    &genai.Schema{Type: genai.TypeObject, MinProperties: genai.Ptr[int64](1), MaxProperties: genai.Ptr[int64](3)},
+				MinItems: genai.Ptr[int64](1),
+				MaxItems: genai.Ptr[int64](5),
+			},
+			want: map[string]any{
+				"type":     "array",
+				"items":    map[string]any{"type": "object", "minProperties": int64(1), "maxProperties": int64(3)},
+				"minItems": int64(1),
+				"maxItems": int64(5),
+			},
+		},
+		{
+			name: "anyOf string or number",
+			schema: &genai.Schema{
+				Description: "An ID or a name",
+				AnyOf: []*genai.Schema{
+					{Type: genai.TypeString},
+					{Type: genai.TypeNumber},
+				},
+			},
+			want: map[string]any{
+				"description": "An ID or a name",
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "number"},
+				},
+			},
+		},
+		{
+			name: "nested anyOf in property",
+			schema: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"key": {AnyOf: []*genai.Schema{
+						{Type: genai.TypeString},
+						{Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeNumber}},
+					}},
+				},
+				Required: []string{"key"},
+			},
+			want: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"key": map[string]any{"anyOf": []any{
+						map[string]any{"type": "string"},
+						map[string]any{"type": "array", "items": map[string]any{"type": "number"}},
+					}},
+				},
+				"required": []string{"key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertSchema(tt.schema, schemaOptions{})
+			if err != nil {
+				t.Fatalf("convertSchema() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("convertSchema() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertSchema_Nullable(t *testing.T) {
+	nullableString := &genai.Schema{Type: genai.TypeString, Description: "A nickname", Nullable: genai.Ptr(true)}
+
+	tests := []struct {
+		name   string
+		schema *genai.Schema
+		style  NullableStyle
+		want   map[string]any
+	}{
+		{
+			name:   "type array",
+			schema: nullableString,
+			style:  NullableTypeArray,
+			want:   map[string]any{"type": []string{"string", "null"}, "description": "A nickname"},
+		},
+		{
+			name:   "anyOf",
+			schema: nullableString,
+			style:  NullableAnyOf,
+			want: map[string]any{
+				"description": "A nickname",
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "null"},
+				},
+			},
+		},
+		{
+			name:   "enum gains null",
+			schema: &genai.Schema{Type: genai.TypeString, Enum: []string{"a", "b"}, Nullable: genai.Ptr(true)},
+			style:  NullableTypeArray,
+			want:   map[string]any{"type": []string{"string", "null"}, "enum": []any{"a", "b", nil}},
+		},
+		{
+			name: "existing anyOf gains null",
+			schema: &genai.Schema{
+				AnyOf:    []*genai.Schema{{Type: genai.TypeString}, {Type: genai.TypeNumber}},
+				Nullable: genai.Ptr(true),
+			},
+			style: NullableTypeArray,
+			want: map[string]any{"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "number"},
+				map[string]any{"type": "null"},
+			}},
+		},
+		{
+			name:   "explicitly not nullable",
+			schema: &genai.Schema{Type: genai.TypeString, Nullable: genai.Ptr(false)},
+			want:   map[string]any{"type": "string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertSchema(tt.schema, schemaOptions{nullable: tt.style})
+			if err != nil {
+				t.Fatalf("convertSchema() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("convertSchema() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStrictJSONSchema(t *testing.T) {
+	schema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"name": {Type: genai.TypeString},
+			"tags": {Type: genai.TypeArray, Items: &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{"label": {Type: genai.TypeString}},
+			}},
+		},
+		Required: []string{"name"},
+	}
+
+	tests := []struct {
+		name   string
+		strict bool
+		want   map[string]any
+	}{
+		{
+			name:   "loose",
+			strict: false,
+			want: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+					"tags": map[string]any{"type": "array", "items": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"label": map[string]any{"type": "string"}},
+					}},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			name:   "strict",
+			strict: true,
+			want: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+					"tags": map[string]any{"type": []string{"array", "null"}, "items": map[string]any{
+						"type":                 "object",
+						"properties":           map[string]any{"label": map[string]any{"type": []string{"string", "null"}}},
+						"required":             []string{"label"},
+						"additionalProperties": false,
+					}},
+				},
+				"required":             []string{"name", "tags"},
+				"additionalProperties": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertSchema(schema, schemaOptions{})
+			if err != nil {
+				t.Fatalf("convertSchema() error = %v", err)
+			}
+			if tt.strict {
+				if err := strictJSONSchema(got, NullableTypeArray); err != nil {
+					t.Fatalf("strictJSONSchema() error = %v", err)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("convertSchema() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStrictJSONSchema_OptionalNullable(t *testing.T) {
+	tests := []struct {
+		name   string
+		style  NullableStyle
+		schema string
+		want   string
+	}{
+		{
+			name:   "type array",
+			schema: `{"type":"object","properties":{"id":{"type":"string"},"note":{"type":"string"}},"required":["id"]}`,
+			want:   `{"additionalProperties":false,"properties":{"id":{"type":"string"},"note":{"type":["string","null"]}},"required":["id","note"],"type":"object"}`,
+		},
+		{
+			name:   "any of",
+			style:  NullableAnyOf,
+			schema: `{"type":"object","properties":{"note":{"type":"string","description":"Free text"}}}`,
+			want:   `{"additionalProperties":false,"properties":{"note":{"anyOf":[{"type":"string"},{"type":"null"}],"description":"Free text"}},"required":["note"],"type":"object"}`,
+		},
+		{
+			name:   "already nullable",
+			schema: `{"type":"object","properties":{"note":{"type":["string","null"]},"ref":{"anyOf":[{"$ref":"#/$defs/x"},{"type":"null"}]}}}`,
+			want:   `{"additionalProperties":false,"properties":{"note":{"type":["string","null"]},"ref":{"anyOf":[{"$ref":"#/$defs/x"},{"type":"null"}]}},"required":["note","ref"],"type":"object"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := toJSONSchemaMap(json.RawMessage(tt.schema))
+			if err != nil {
+				t.Fatalf("toJSONSchemaMap() error = %v", err)
+			}
+			if err := strictJSONSchema(schema, tt.style); err != nil {
+				t.Fatalf("strictJSONSchema() error = %v", err)
+			}
+			data, err := json.Marshal(schema)
+			if err != nil {
+				t.Fatalf("failed to marshal schema: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("schema = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_ResponseSchema(t *testing.T) {
+	m := &OpenAIModel{ModelName: "gpt-4o"}
+	got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Describe a cat"}}}},
+		Config: &genai.GenerateContentConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema: &genai.Schema{
+				Type:       genai.TypeObject,
+				Properties: map[string]*genai.Schema{"name": {Type: genai.TypeString}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+
+	format := got.ResponseFormat
+	if format == nil || format.Type != openai.ChatCompletionResponseFormatTypeJSONSchema || format.JSONSchema == nil {
+		t.Fatalf("ResponseFormat = %+v, want json_schema", format)
+	}
+	if !format.JSONSchema.Strict {
+		t.Error("JSONSchema.Strict = false, want true")
+	}
+	data, err := json.Marshal(format.JSONSchema.Schema)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	want := `{"additionalProperties":false,"properties":{"name":{"type":["string","null"]}},"required":["name"],"type":"object"}`
+	if string(data) != want {
+		t.Errorf("schema = %s, want %s", data, want)
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_ResponseSchemaFormat(t *testing.T) {
+	name := &genai.Schema{Type: genai.TypeString}
+	tests := []struct {
+		name       string
+		format     ResponseSchemaFormat
+		prop       *genai.Schema
+		wantType   openai.ChatCompletionResponseFormatType
+		wantStrict bool
+		wantSchema string
+	}{
+		{
+			name:       "strict",
+			prop:       name,
+			wantType:   openai.ChatCompletionResponseFormatTypeJSONSchema,
+			wantStrict: true,
+			wantSchema: `{"additionalProperties":false,"properties":{"name":{"type":["string","null"]}},"required":["name"],"type":"object"}`,
+		},
+		{
+			name:       "strict falls back for unsupported keywords",
+			prop:       &genai.Schema{Type: genai.TypeString, MinLength: genai.Ptr[int64](1)},
+			wantType:   openai.ChatCompletionResponseFormatTypeJSONSchema,
+			wantSchema: `{"properties":{"name":{"minLength":1,"type":"string"}},"type":"object"}`,
+		},
+		{
+			name:       "loose",
+			format:     ResponseSchemaLoose,
+			prop:       name,
+			wantType:   openai.ChatCompletionResponseFormatTypeJSONSchema,
+			wantSchema: `{"properties":{"name":{"type":"string"}},"type":"object"}`,
+		},
+		{
+			name:     "json object",
+			format:   ResponseSchemaJSONObject,
+			prop:     name,
+			wantType: openai.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{ModelName: "gpt-4o", ResponseSchemaFormat: tt.format}
+			got, err := m.toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Describe a cat"}}}},
+				Config: &genai.GenerateContentConfig{
+					ResponseSchema: &genai.Schema{
+						Type:       genai.TypeObject,
+						Properties: map[string]*genai.Schema{"name": tt.prop},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+
+			format := got.ResponseFormat
+			if format == nil || format.Type != tt.wantType {
+				t.Fatalf("ResponseFormat = %+v, want type %q", format, tt.wantType)
+			}
+			if tt.wantSchema == "" {
+				if format.JSONSchema != nil {
+					t.Errorf("JSONSchema = %+v, want none", format.JSONSchema)
+				}
+				return
+			}
+			if format.JSONSchema.Strict != tt.wantStrict {
+				t.Errorf("JSONSchema.Strict = %v, want %v", format.JSONSchema.Strict, tt.wantStrict)
+			}
+			data, err := json.Marshal(format.JSONSchema.Schema)
+			if err != nil {
+				t.Fatalf("failed to marshal schema: %v", err)
+			}
+			if string(data) != tt.wantSchema {
+				t.Errorf("schema = %s, want %s", data, tt.wantSchema)
+			}
+		})
+	}
+}
+
+func TestConvertTools_SchemaParameters(t *testing.T) {
+	got, err := convertTools([]*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+		Name: "lookup",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"id": {AnyOf: []*genai.Schema{{Type: genai.TypeString}, {Type: genai.TypeNumber}}},
+			},
+		},
+	}}}}, schemaOptions{})
+	if err != nil {
+		t.Fatalf("convertTools() error = %v", err)
+	}
+
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "number"},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, got[0].Function.Parameters); diff != "" {
+		t.Errorf("Parameters mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertTools_Strict(t *testing.T) {
+	tools := func(params *genai.Schema) []*genai.Tool {
+		return []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "lookup", Parameters: params}}}}
+	}
+
+	got, err := convertTools(tools(&genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"id":    {Type: genai.TypeString},
+			"limit": {Type: genai.TypeInteger, Nullable: genai.Ptr(true)},
+		},
+		Required: []string{"id"},
+	}), schemaOptions{strict: true})
+	if err != nil {
+		t.Fatalf("convertTools() error = %v", err)
+	}
+	if !got[0].Function.Strict {
+		t.Error("Function.Strict = false, want true")
+	}
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":    map[string]any{"type": "string"},
+			"limit": map[string]any{"type": []any{"integer", "null"}},
+		},
+		"required":             []string{"id", "limit"},
+		"additionalProperties": false,
+	}
+	if diff := cmp.Diff(want, got[0].Function.Parameters); diff != "" {
+		t.Errorf("Parameters mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = convertTools(tools(&genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"id": {Type: genai.TypeString, MinLength: genai.Ptr[int64](1)}},
+	}), schemaOptions{strict: true})
+	if err == nil || !strings.Contains(err.Error(), "minLength") {
+		t.Errorf("convertTools() error = %v, want unsupported minLength error", err)
+	}
+}
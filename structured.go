@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+)
+
+// ErrNoStructuredContent is returned by ParseStructured for a response
+// without text to decode.
+var ErrNoStructuredContent = errors.New("response has no text content to parse")
+
+// ParseStructured decodes the text of resp, as produced with a JSON response
+// format, into a T. Thought parts are skipped and the remaining text parts are
+// concatenated before decoding.
+func ParseStructured[T any](resp *model.LLMResponse) (T, error) {
+	var value T
+	if resp == nil || resp.Content == nil {
+		return value, ErrNoStructuredContent
+	}
+	var text strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part != nil && !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return value, ErrNoStructuredContent
+	}
+	if err := json.Unmarshal([]byte(text.String()), &value); err != nil {
+		return value, fmt.Errorf("failed to parse structured response as %T: %w", value, err)
+	}
+	return value, nil
+}
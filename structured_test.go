@@ -0,0 +1,52 @@
+package openai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+type recipe struct {
+	Name     string   `json:"name"`
+	Minutes  int      `json:"minutes"`
+	Tags     []string `json:"tags"`
+	Optional *string  `json:"optional"`
+}
+
+func TestParseStructured(t *testing.T) {
+	resp := &model.LLMResponse{Content: &genai.Content{Role: "model", Parts: []*genai.Part{
+		{Text: "Picking something quick.", Thought: true},
+		{Text: `{"name":"Pancakes",`},
+		{Text: `"minutes":20,"tags":["breakfast"]}`},
+	}}}
+
+	got, err := ParseStructured[recipe](resp)
+	if err != nil {
+		t.Fatalf("ParseStructured() error = %v", err)
+	}
+	want := recipe{Name: "Pancakes", Minutes: 20, Tags: []string{"breakfast"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseStructured() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseStructured_Errors(t *testing.T) {
+	malformed := &model.LLMResponse{Content: &genai.Content{Parts: []*genai.Part{{Text: `{"name":"Pancakes",`}}}}
+	if _, err := ParseStructured[recipe](malformed); err == nil || !strings.Contains(err.Error(), "openai.recipe") {
+		t.Errorf("ParseStructured() error = %v, want a parse error naming the type", err)
+	}
+
+	for _, resp := range []*model.LLMResponse{
+		nil,
+		{},
+		{Content: &genai.Content{Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: "lookup"}}}}},
+	} {
+		if _, err := ParseStructured[recipe](resp); !errors.Is(err, ErrNoStructuredContent) {
+			t.Errorf("ParseStructured(%+v) error = %v, want ErrNoStructuredContent", resp, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package openai
+
+import (
+	"context"
+
+	"google.golang.org/genai"
+)
+
+// temperatureKey is the context key for the per-call temperature override.
+type temperatureKey struct{}
+
+// ContextWithTemperature returns a copy of ctx whose requests are sampled at
+// temperature. It takes precedence over the request's Config.Temperature,
+// which in turn takes precedence over OpenAIModel.DefaultConfig.
+func ContextWithTemperature(ctx context.Context, temperature float32) context.Context {
+	return context.WithValue(ctx, temperatureKey{}, temperature)
+}
+
+// temperature returns the sampling temperature for a request made under ctx
+// with cfg, or nil to leave it to the backend.
+func temperature(ctx context.Context, cfg *genai.GenerateContentConfig) *float32 {
+	if temperature, ok := ctx.Value(temperatureKey{}).(float32); ok {
+		return &temperature
+	}
+	return cfg.Temperature
+}
@@ -0,0 +1,48 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_Temperature(t *testing.T) {
+	tests := []struct {
+		name         string
+		modelDefault *float32
+		reqTemp      *float32
+		ctxTemp      *float32
+		want         float32
+	}{
+		{name: "unset"},
+		{name: "model default", modelDefault: genai.Ptr[float32](0.2), want: 0.2},
+		{name: "request overrides model", modelDefault: genai.Ptr[float32](0.2), reqTemp: genai.Ptr[float32](0.5), want: 0.5},
+		{name: "context overrides request", modelDefault: genai.Ptr[float32](0.2), reqTemp: genai.Ptr[float32](0.5), ctxTemp: genai.Ptr[float32](0.9), want: 0.9},
+		{name: "context only", ctxTemp: genai.Ptr[float32](1.1), want: 1.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctxTemp != nil {
+				ctx = ContextWithTemperature(ctx, *tt.ctxTemp)
+			}
+			m := &OpenAIModel{ModelName: "gpt-4o"}
+			if tt.modelDefault != nil {
+				m.DefaultConfig = &genai.GenerateContentConfig{Temperature: tt.modelDefault}
+			}
+			got, err := m.toOpenAIChatCompletionRequest(ctx, &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+				Config:   &genai.GenerateContentConfig{Temperature: tt.reqTemp},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.Temperature != tt.want {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package openai
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+// splitThoughts separates the thought parts of content, such as replayed
+// reasoning from an earlier turn, from the parts to send. It returns a copy of
+// content without them and their joined text.
+func splitThoughts(content *genai.Content) (*genai.Content, string) {
+	var reasoning strings.Builder
+	parts := make([]*genai.Part, 0, len(content.Parts))
+	for _, part := range content.Parts {
+		if part.Thought {
+			reasoning.WriteString(part.Text)
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == len(content.Parts) {
+		return content, ""
+	}
+	return &genai.Content{Role: content.Role, Parts: parts}, reasoning.String()
+}
+
+// setReasoningContent replays reasoning on the assistant message of msgs
+// when ReplayReasoning is set.
+func (o *OpenAIModel) setReasoningContent(msgs []openai.ChatCompletionMessage, reasoning string) {
+	if !o.ReplayReasoning || reasoning == "" {
+		return
+	}
+	for i := range msgs {
+		if msgs[i].Role == openai.ChatMessageRoleAssistant {
+			msgs[i].ReasoningContent = reasoning
+		}
+	}
+}
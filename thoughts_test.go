@@ -0,0 +1,62 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionMessage_Thoughts(t *testing.T) {
+	turn := &genai.Content{
+		Role: "model",
+		Parts: []*genai.Part{
+			{Text: "The user wants a greeting.", Thought: true},
+			{Text: "Hello!"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		m       *OpenAIModel
+		content *genai.Content
+		want    []openai.ChatCompletionMessage
+	}{
+		{
+			name:    "stripped for OpenAI",
+			m:       &OpenAIModel{},
+			content: turn,
+			want:    []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleAssistant, Content: "Hello!"}},
+		},
+		{
+			name:    "replayed as reasoning",
+			m:       &OpenAIModel{ReplayReasoning: true},
+			content: turn,
+			want: []openai.ChatCompletionMessage{{
+				Role:             openai.ChatMessageRoleAssistant,
+				Content:          "Hello!",
+				ReasoningContent: "The user wants a greeting.",
+			}},
+		},
+		{
+			name: "thought only turn is dropped",
+			m:    &OpenAIModel{},
+			content: &genai.Content{Role: "model", Parts: []*genai.Part{
+				{Text: "Thinking...", Thought: true},
+			}},
+			want: []openai.ChatCompletionMessage{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.toOpenAIChatCompletionMessage(tt.content)
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("messages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
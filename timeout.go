@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// requestContext returns ctx bounded by RequestTimeout, if one is set.
+func (o *OpenAIModel) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.RequestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.RequestTimeout)
+}
+
+// streamContext returns the context a streaming call runs under: bounded by
+// RequestTimeout as a whole, or unbounded when the timeout only covers
+// opening the stream.
+func (o *OpenAIModel) streamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.RequestTimeoutConnectOnly {
+		return context.WithCancel(ctx)
+	}
+	return o.requestContext(ctx)
+}
+
+// createChatCompletionStream opens a chat completion stream. With
+// RequestTimeoutConnectOnly, RequestTimeout bounds only this call: ctx is
+// cancelled if the stream has not started in time, and left alone once it
+// has. cancel must cancel ctx.
+func (o *OpenAIModel) createChatCompletionStream(ctx context.Context, cancel context.CancelFunc, req openai.ChatCompletionRequest) (chatCompletionStream, error) {
+	if !o.RequestTimeoutConnectOnly || o.RequestTimeout <= 0 {
+		return o.Client.CreateChatCompletionStream(ctx, req)
+	}
+
+	timer := time.AfterFunc(o.RequestTimeout, cancel)
+	stream, err := o.Client.CreateChatCompletionStream(ctx, req)
+	if timer.Stop() {
+		return stream, err
+	}
+	if err == nil {
+		stream.Close()
+	}
+	return nil, fmt.Errorf("stream did not start within %v: %w", o.RequestTimeout, context.DeadlineExceeded)
+}
@@ -0,0 +1,62 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestGenerateContent_RequestTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	tests := []struct {
+		name        string
+		headerDelay time.Duration
+		chunkDelay  time.Duration
+		stream      bool
+		connectOnly bool
+		wantTimeout bool
+	}{
+		{name: "fast call", stream: false},
+		{name: "slow call", headerDelay: 4 * timeout, wantTimeout: true},
+		{name: "slow stream start", headerDelay: 4 * timeout, stream: true, wantTimeout: true},
+		{name: "long stream", chunkDelay: timeout, stream: true, wantTimeout: true},
+		{name: "slow stream start, connect only", headerDelay: 4 * timeout, stream: true, connectOnly: true, wantTimeout: true},
+		{name: "long stream, connect only", chunkDelay: timeout, stream: true, connectOnly: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, m := newFakeServer(t)
+			s.delay = tt.headerDelay
+			s.chunkDelay = tt.chunkDelay
+			s.chunks = []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "a"}, ""),
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "b"}, ""),
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "c"}, openai.FinishReasonStop),
+			}
+			m.RequestTimeout = timeout
+			m.RequestTimeoutConnectOnly = tt.connectOnly
+
+			var gotErr error
+			for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+			}, tt.stream) {
+				if err != nil {
+					gotErr = err
+				}
+			}
+			if tt.wantTimeout {
+				if !errors.Is(gotErr, context.DeadlineExceeded) {
+					t.Errorf("GenerateContent() error = %v, want a deadline exceeded error", gotErr)
+				}
+			} else if gotErr != nil {
+				t.Errorf("GenerateContent() error = %v", gotErr)
+			}
+		})
+	}
+}
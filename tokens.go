@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+)
+
+// Per-message overheads documented in the OpenAI cookbook for the chat format.
+const (
+	tokensPerMessage = 3
+	tokensPerName    = 1
+	tokensPerReply   = 3
+)
+
+var (
+	offlineEncodingsOnce sync.Once
+	encodings            sync.Map // encoding name -> *tiktoken.Tiktoken
+)
+
+// UseOfflineEncodings makes tiktoken-go load encodings from the BPE ranks
+// embedded in tiktoken-go-loader instead of downloading them, so that
+// CountTokens and StreamUsageEstimates need no network access. tiktoken-go's
+// loader is process-wide: this replaces any loader set with
+// tiktoken.SetBpeLoader, so call it at startup and only if the application
+// has not configured its own.
+func UseOfflineEncodings() {
+	offlineEncodingsOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	})
+}
+
+// CountTokens estimates the number of prompt tokens req will use, including
+// the system instruction and the per-message overhead of the chat format.
+// Image parts and tool definitions are not counted. Encodings are downloaded
+// on first use unless UseOfflineEncodings was called.
+func (o *OpenAIModel) CountTokens(ctx context.Context, req *model.LLMRequest) (int, error) {
+	openaiReq, err := o.toOpenAIChatCompletionRequest(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	enc, err := encodingForModel(o.ModelName)
+	if err != nil {
+		return 0, err
+	}
+	return countMessageTokens(enc, openaiReq.Messages), nil
+}
+
+// encodingForModel returns the tokenizer for modelName, loaded through
+// tiktoken-go's current BPE loader; see UseOfflineEncodings.
+func encodingForModel(modelName string) (*tiktoken.Tiktoken, error) {
+	name := capabilitiesForModel(modelName).encoding
+	if enc, ok := encodings.Load(name); ok {
+		return enc.(*tiktoken.Tiktoken), nil
+	}
+
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s encoding: %w", name, err)
+	}
+	actual, _ := encodings.LoadOrStore(name, enc)
+	return actual.(*tiktoken.Tiktoken), nil
+}
+
+// countMessageTokens counts the tokens of msgs as laid out by the chat format.
+func countMessageTokens(enc *tiktoken.Tiktoken, msgs []openai.ChatCompletionMessage) int {
+	count := func(s string) int {
+		return len(enc.Encode(s, nil, nil))
+	}
+
+	total := 0
+	for _, msg := range msgs {
+		total += tokensPerMessage
+		total += count(msg.Role)
+		total += count(msg.Content)
+		for _, part := range msg.MultiContent {
+			if part.Type == openai.ChatMessagePartTypeText {
+				total += count(part.Text)
+			}
+		}
+		if msg.Name != "" {
+			total += tokensPerName + count(msg.Name)
+		}
+		for _, toolCall := range msg.ToolCalls {
+			total += count(toolCall.Function.Name) + count(toolCall.Function.Arguments)
+		}
+	}
+	return total + tokensPerReply
+}
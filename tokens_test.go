@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// cookbookMessages is the example conversation from the OpenAI cookbook
+// "How to count tokens with tiktoken".
+var cookbookMessages = []openai.ChatCompletionMessage{
+	{Role: "system", Content: "You are a helpful, pattern-following assistant that translates corporate jargon into plain English."},
+	{Role: "system", Name: "example_user", Content: "New synergies will help drive top-line growth."},
+	{Role: "system", Name: "example_assistant", Content: "Things working in concert will increase revenue."},
+	{Role: "system", Name: "example_user", Content: "Let's circle back when we have more bandwidth to touch base on opportunities for increased leverage."},
+	{Role: "system", Name: "example_assistant", Content: "Let's talk later when we're less busy about how to do better."},
+	{Role: "user", Content: "This late pivot means we don't have time to boil the ocean for the client deliverable."},
+}
+
+func TestCountMessageTokens(t *testing.T) {
+	UseOfflineEncodings()
+	tests := []struct {
+		modelName string
+		want      int
+	}{
+		{modelName: "gpt-3.5-turbo", want: 129},
+		{modelName: "gpt-4", want: 129},
+		{modelName: "gpt-4o", want: 124},
+		{modelName: "gpt-4o-mini", want: 124},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelName, func(t *testing.T) {
+			enc, err := encodingForModel(tt.modelName)
+			if err != nil {
+				t.Fatalf("encodingForModel() error = %v", err)
+			}
+			if got := countMessageTokens(enc, cookbookMessages); got != tt.want {
+				t.Errorf("countMessageTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIModel_CountTokens(t *testing.T) {
+	UseOfflineEncodings()
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}},
+		},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: "You are a helpful assistant."}}},
+		},
+	}
+
+	m := &OpenAIModel{ModelName: "gpt-4o"}
+	got, err := m.CountTokens(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	// 3 per message + role + content for each message, plus 3 for the reply.
+	if want := 18; got != want {
+		t.Errorf("CountTokens() = %d, want %d", got, want)
+	}
+}
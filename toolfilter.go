@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/genai"
+)
+
+// allowedToolsKey is the context key for the per-call tool allow-list.
+type allowedToolsKey struct{}
+
+// ContextWithAllowedTools returns a copy of ctx whose requests only offer the
+// model the function tools named in names, out of those in the request's
+// Config.Tools. Names matching no declared function are ignored, with a
+// warning when a logger is set through WithLogger.
+func ContextWithAllowedTools(ctx context.Context, names ...string) context.Context {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return context.WithValue(ctx, allowedToolsKey{}, allowed)
+}
+
+// allowedTools returns tools reduced to the function declarations allowed by
+// ctx. Tools left without declarations are dropped unless they enable
+// something else. tools itself is not modified.
+func (o *OpenAIModel) allowedTools(ctx context.Context, tools []*genai.Tool) []*genai.Tool {
+	allowed, ok := ctx.Value(allowedToolsKey{}).(map[string]bool)
+	if !ok {
+		return tools
+	}
+
+	declared := map[string]bool{}
+	var filtered []*genai.Tool
+	for _, tool := range tools {
+		if tool == nil || len(tool.FunctionDeclarations) == 0 {
+			filtered = append(filtered, tool)
+			continue
+		}
+		var decls []*genai.FunctionDeclaration
+		for _, decl := range tool.FunctionDeclarations {
+			if decl == nil {
+				decls = append(decls, decl)
+				continue
+			}
+			declared[decl.Name] = true
+			if allowed[decl.Name] {
+				decls = append(decls, decl)
+			}
+		}
+		reduced := *tool
+		reduced.FunctionDeclarations = decls
+		if len(decls) > 0 || len(builtinTools(&reduced)) > 0 {
+			filtered = append(filtered, &reduced)
+		}
+	}
+
+	if o.logger != nil {
+		for name := range allowed {
+			if !declared[name] {
+				o.logger.LogAttrs(ctx, slog.LevelWarn, "allowed tool is not declared", slog.String("tool", name))
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,65 @@
+package openai
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_AllowedTools(t *testing.T) {
+	decl := func(name string) *genai.FunctionDeclaration {
+		return &genai.FunctionDeclaration{Name: name, Parameters: &genai.Schema{Type: genai.TypeObject}}
+	}
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Plan my trip"}}}},
+		Config: &genai.GenerateContentConfig{Tools: []*genai.Tool{
+			{FunctionDeclarations: []*genai.FunctionDeclaration{decl("get_weather"), decl("book_flight")}},
+			{FunctionDeclarations: []*genai.FunctionDeclaration{decl("send_email")}},
+		}},
+	}
+	toolNames := func(t *testing.T, m *OpenAIModel, ctx context.Context) []string {
+		t.Helper()
+		got, err := m.toOpenAIChatCompletionRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+		}
+		var names []string
+		for _, tool := range got.Tools {
+			names = append(names, tool.Function.Name)
+		}
+		return names
+	}
+
+	t.Run("unfiltered", func(t *testing.T) {
+		got := toolNames(t, &OpenAIModel{ModelName: "gpt-4o"}, context.Background())
+		if diff := cmp.Diff([]string{"get_weather", "book_flight", "send_email"}, got); diff != "" {
+			t.Errorf("tools mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("allow-list", func(t *testing.T) {
+		handler := &recordHandler{}
+		m := NewOpenAIModelWithAPIKey("gpt-4o", "test", WithLogger(slog.New(handler)))
+		ctx := ContextWithAllowedTools(context.Background(), "book_flight", "send_email", "cancel_trip")
+		if diff := cmp.Diff([]string{"book_flight", "send_email"}, toolNames(t, m, ctx)); diff != "" {
+			t.Errorf("tools mismatch (-want +got):\n%s", diff)
+		}
+		if got := handler.attrs(t, "allowed tool is not declared")["tool"].String(); got != "cancel_trip" {
+			t.Errorf("warned about %q, want cancel_trip", got)
+		}
+		if len(req.Config.Tools[0].FunctionDeclarations) != 2 {
+			t.Error("request tools were modified")
+		}
+	})
+
+	t.Run("empty allow-list", func(t *testing.T) {
+		ctx := ContextWithAllowedTools(context.Background())
+		if got := toolNames(t, &OpenAIModel{ModelName: "gpt-4o"}, ctx); len(got) != 0 {
+			t.Errorf("tools = %v, want none", got)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package openai
+
+import "google.golang.org/genai"
+
+// isToolRole reports whether role marks content holding tool results, as the
+// "function" and "tool" roles of older stored conversations do.
+func isToolRole(role string) bool {
+	return role == "function" || role == "tool"
+}
+
+// linkFunctionResponses returns contents with tool results tied to the calls
+// they answer. A function response without an ID takes the ID of the earliest
+// unanswered preceding call of the same name, and text-only content under a
+// tool role becomes a function response to the earliest unanswered call, its
+// text parts joined by sep. Without such a call, the content is sent as a
+// user message, since backends reject tool messages that answer no call.
+// contents itself is not modified.
+func linkFunctionResponses(contents []*genai.Content, sep string) []*genai.Content {
+	type call struct{ id, name string }
+	var pending []call
+	take := func(name string) (call, bool) {
+		for i, c := range pending {
+			if name == "" || c.name == name {
+				pending = append(pending[:i:i], pending[i+1:]...)
+				return c, true
+			}
+		}
+		return call{}, false
+	}
+
+	linked := contents
+	copied := false
+	replace := func(i int, content *genai.Content) {
+		if !copied {
+			linked = append([]*genai.Content(nil), contents...)
+			copied = true
+		}
+		linked[i] = content
+	}
+
+	for i, content := range contents {
+		if content == nil {
+			continue
+		}
+		if isToolRole(content.Role) && !hasFunctionResponse(content) {
+			if c, ok := take(""); ok && c.id != "" {
+				replace(i, &genai.Content{Role: content.Role, Parts: []*genai.Part{{
+					FunctionResponse: &genai.FunctionResponse{
+						ID:       c.id,
+						Name:     c.name,
+						Response: map[string]any{"result": extractTextFromContent(content, sep)},
+					},
+				}}})
+			} else {
+				replace(i, &genai.Content{Role: genai.RoleUser, Parts: content.Parts})
+			}
+			continue
+		}
+
+		var parts []*genai.Part
+		for j, part := range content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				pending = append(pending, call{id: part.FunctionCall.ID, name: part.FunctionCall.Name})
+			case part.FunctionResponse != nil && part.FunctionResponse.ID != "":
+				for k, c := range pending {
+					if c.id == part.FunctionResponse.ID {
+						pending = append(pending[:k:k], pending[k+1:]...)
+						break
+					}
+				}
+			case part.FunctionResponse != nil:
+				c, ok := take(part.FunctionResponse.Name)
+				if !ok || c.id == "" {
+					continue
+				}
+				if parts == nil {
+					parts = append([]*genai.Part(nil), content.Parts...)
+				}
+				resp := *part.FunctionResponse
+				resp.ID = c.id
+				filled := *part
+				filled.FunctionResponse = &resp
+				parts[j] = &filled
+			}
+		}
+		if parts != nil {
+			replace(i, &genai.Content{Role: content.Role, Parts: parts})
+		}
+	}
+	return linked
+}
+
+// hasFunctionResponse reports whether content has a function response part.
+func hasFunctionResponse(content *genai.Content) bool {
+	for _, part := range content.Parts {
+		if part.FunctionResponse != nil {
+			return true
+		}
+	}
+	return false
+}
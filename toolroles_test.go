@@ -0,0 +1,105 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_ToolRoles(t *testing.T) {
+	call := &genai.Content{Role: "model", Parts: []*genai.Part{{
+		FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"city": "Paris"}},
+	}}}
+	wantToolMsg := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"}
+
+	tests := []struct {
+		name   string
+		result *genai.Content
+	}{
+		{
+			name:   "legacy function role with text",
+			result: &genai.Content{Role: "function", Parts: []*genai.Part{{Text: "sunny"}}},
+		},
+		{
+			name:   "tool role with text",
+			result: &genai.Content{Role: "tool", Parts: []*genai.Part{{Text: "sunny"}}},
+		},
+		{
+			name: "function response without ID",
+			result: &genai.Content{Role: "function", Parts: []*genai.Part{{
+				FunctionResponse: &genai.FunctionResponse{Name: "get_weather", Response: map[string]any{"result": "sunny"}},
+			}}},
+		},
+		{
+			name: "current convention",
+			result: &genai.Content{Role: "user", Parts: []*genai.Part{{
+				FunctionResponse: &genai.FunctionResponse{ID: "call_1", Name: "get_weather", Response: map[string]any{"result": "sunny"}},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := cloneContent(tt.result)
+			contents := []*genai.Content{
+				{Role: "user", Parts: []*genai.Part{{Text: "Weather in Paris?"}}},
+				call,
+				tt.result,
+			}
+			got, err := (&OpenAIModel{}).toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{Contents: contents})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if len(got.Messages) != 3 {
+				t.Fatalf("got %d messages, want 3", len(got.Messages))
+			}
+			if diff := cmp.Diff(wantToolMsg, got.Messages[2]); diff != "" {
+				t.Errorf("tool message mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(before, tt.result); diff != "" {
+				t.Errorf("request contents were modified (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToOpenAIChatCompletionRequest_UnlinkedToolRole(t *testing.T) {
+	result := &genai.Content{Role: "tool", Parts: []*genai.Part{{Text: "sunny"}}}
+	before := cloneContent(result)
+	got, err := (&OpenAIModel{}).toOpenAIChatCompletionRequest(context.Background(), &model.LLMRequest{
+		Contents: []*genai.Content{
+			{Role: "user", Parts: []*genai.Part{{Text: "Weather in Paris?"}}},
+			result,
+		},
+	})
+	if err != nil {
+		t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+	}
+	want := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "Weather in Paris?"},
+		{Role: openai.ChatMessageRoleUser, Content: "sunny"},
+	}
+	if diff := cmp.Diff(want, got.Messages); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(before, result); diff != "" {
+		t.Errorf("request contents were modified (-want +got):\n%s", diff)
+	}
+}
+
+// cloneContent returns a deep copy of content.
+func cloneContent(content *genai.Content) *genai.Content {
+	clone := &genai.Content{Role: content.Role}
+	for _, part := range content.Parts {
+		p := *part
+		if part.FunctionResponse != nil {
+			resp := *part.FunctionResponse
+			p.FunctionResponse = &resp
+		}
+		clone.Parts = append(clone.Parts, &p)
+	}
+	return clone
+}
@@ -0,0 +1,39 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// UnsupportedToolsError reports genai built-in tools, such as Google Search or
+// code execution, that have no chat completions counterpart. Tools lists them
+// by their genai JSON names.
+type UnsupportedToolsError struct {
+	Tools []string
+}
+
+func (e *UnsupportedToolsError) Error() string {
+	return fmt.Sprintf("unsupported built-in tools: %s", strings.Join(e.Tools, ", "))
+}
+
+// builtinTools returns the JSON names of the built-in tools tool enables.
+func builtinTools(tool *genai.Tool) []string {
+	var names []string
+	add := func(enabled bool, name string) {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	add(tool.Retrieval != nil, "retrieval")
+	add(tool.GoogleSearchRetrieval != nil, "googleSearchRetrieval")
+	add(tool.ComputerUse != nil, "computerUse")
+	add(tool.FileSearch != nil, "fileSearch")
+	add(tool.CodeExecution != nil, "codeExecution")
+	add(tool.EnterpriseWebSearch != nil, "enterpriseWebSearch")
+	add(tool.GoogleMaps != nil, "googleMaps")
+	add(tool.GoogleSearch != nil, "googleSearch")
+	add(tool.URLContext != nil, "urlContext")
+	return names
+}
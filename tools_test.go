@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestConvertTools_InvalidTools(t *testing.T) {
+	lookup := &genai.FunctionDeclaration{
+		Name:       "lookup",
+		Parameters: &genai.Schema{Type: genai.TypeObject},
+	}
+	tests := []struct {
+		name      string
+		tools     []*genai.Tool
+		lenient   bool
+		wantErr   string
+		wantTools []string
+		wantNames []string
+	}{
+		{
+			name:    "nil declaration",
+			tools:   []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{lookup, nil}}},
+			wantErr: "function declaration 1 of tool 0 is nil",
+		},
+		{
+			name:      "nil declaration skipped",
+			tools:     []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{nil, lookup}}},
+			lenient:   true,
+			wantNames: []string{"lookup"},
+		},
+		{
+			name: "unsupported built-in tools",
+			tools: []*genai.Tool{
+				{FunctionDeclarations: []*genai.FunctionDeclaration{lookup}},
+				{GoogleSearch: &genai.GoogleSearch{}},
+				{CodeExecution: &genai.ToolCodeExecution{}},
+			},
+			wantTools: []string{"googleSearch", "codeExecution"},
+		},
+		{
+			name: "unsupported built-in tools skipped",
+			tools: []*genai.Tool{
+				{FunctionDeclarations: []*genai.FunctionDeclaration{lookup}, GoogleSearch: &genai.GoogleSearch{}},
+			},
+			lenient:   true,
+			wantNames: []string{"lookup"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertTools(tt.tools, schemaOptions{lenientTools: tt.lenient})
+
+			var toolsErr *UnsupportedToolsError
+			switch {
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("convertTools() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			case tt.wantTools != nil:
+				if !errors.As(err, &toolsErr) {
+					t.Fatalf("convertTools() error = %v, want UnsupportedToolsError", err)
+				}
+				if diff := cmp.Diff(tt.wantTools, toolsErr.Tools); diff != "" {
+					t.Errorf("Tools mismatch (-want +got):\n%s", diff)
+				}
+				return
+			case err != nil:
+				t.Fatalf("convertTools() error = %v", err)
+			}
+
+			var names []string
+			for _, tool := range got {
+				names = append(names, tool.Function.Name)
+			}
+			if diff := cmp.Diff(tt.wantNames, names); diff != "" {
+				t.Errorf("tool names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
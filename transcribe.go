@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// audioExtensions maps the audio MIME types the transcriptions endpoint
+// accepts to the file extension it infers the format from.
+var audioExtensions = map[string]string{
+	"audio/flac":  "flac",
+	"audio/m4a":   "m4a",
+	"audio/mp3":   "mp3",
+	"audio/mp4":   "mp4",
+	"audio/mpeg":  "mp3",
+	"audio/ogg":   "ogg",
+	"audio/wav":   "wav",
+	"audio/wave":  "wav",
+	"audio/webm":  "webm",
+	"audio/x-m4a": "m4a",
+	"audio/x-wav": "wav",
+}
+
+// OpenAITranscriber converts speech to text through the OpenAI transcriptions
+// endpoint, sharing client configuration with OpenAIModel. ModelName selects
+// the speech model, such as whisper-1 or gpt-4o-transcribe.
+type OpenAITranscriber struct {
+	Client    *openai.Client
+	ModelName string
+
+	// Language is the ISO-639-1 code of the spoken language. Empty lets the
+	// model detect it.
+	Language string
+}
+
+// Transcription is a transcript along with its timed segments.
+type Transcription struct {
+	Text     string
+	Language string
+	Segments []TranscriptionSegment
+}
+
+// TranscriptionSegment is a stretch of the transcript, timed in seconds from
+// the start of the audio.
+type TranscriptionSegment struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+func NewOpenAITranscriberWithAPIKey(modelName string, apiKey string) *OpenAITranscriber {
+	cfg := openai.DefaultConfig(apiKey)
+	return NewOpenAITranscriber(modelName, cfg)
+}
+
+func NewOpenAITranscriber(modelName string, cfg openai.ClientConfig) *OpenAITranscriber {
+	client := openai.NewClientWithConfig(cfg)
+	return &OpenAITranscriber{
+		Client:    client,
+		ModelName: modelName,
+	}
+}
+
+// Transcribe returns the plain transcript of audio, whose format is given by
+// mimeType, such as audio/mpeg or audio/wav.
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	resp, err := t.transcribe(ctx, audio, mimeType, openai.AudioResponseFormatJSON)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// TranscribeSegments returns the transcript of audio split into timed
+// segments. Only whisper-1 reports segments; other models fail the request.
+func (t *OpenAITranscriber) TranscribeSegments(ctx context.Context, audio []byte, mimeType string) (*Transcription, error) {
+	resp, err := t.transcribe(ctx, audio, mimeType, openai.AudioResponseFormatVerboseJSON)
+	if err != nil {
+		return nil, err
+	}
+	transcription := &Transcription{Text: resp.Text, Language: resp.Language}
+	for _, segment := range resp.Segments {
+		transcription.Segments = append(transcription.Segments, TranscriptionSegment{
+			Start: segment.Start,
+			End:   segment.End,
+			Text:  segment.Text,
+		})
+	}
+	return transcription, nil
+}
+
+func (t *OpenAITranscriber) transcribe(ctx context.Context, audio []byte, mimeType string, format openai.AudioResponseFormat) (openai.AudioResponse, error) {
+	ext, ok := audioExtensions[mimeType]
+	if !ok {
+		return openai.AudioResponse{}, fmt.Errorf("unsupported audio type %q", mimeType)
+	}
+	req := openai.AudioRequest{
+		Model:    t.ModelName,
+		FilePath: "audio." + ext,
+		Reader:   bytes.NewReader(audio),
+		Language: t.Language,
+		Format:   format,
+	}
+	if format == openai.AudioResponseFormatVerboseJSON {
+		req.TimestampGranularities = []openai.TranscriptionTimestampGranularity{openai.TranscriptionTimestampGranularitySegment}
+	}
+	return t.Client.CreateTranscription(ctx, req)
+}
@@ -0,0 +1,116 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sashabaranov/go-openai"
+)
+
+// transcriptionUpload is what the fake transcriptions server received.
+type transcriptionUpload struct {
+	filename string
+	audio    string
+	fields   map[string]string
+}
+
+// newFakeTranscriptionServer returns a transcriber pointed at a server that
+// answers with a fixed transcript.
+func newFakeTranscriptionServer(t *testing.T) (*fakeServer, *OpenAITranscriber) {
+	t.Helper()
+	s, _ := newFakeServer(t)
+	s.handle("/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"text":     "Hello there. General Kenobi.",
+			"language": "english",
+			"segments": []map[string]any{
+				{"start": 0.0, "end": 1.2, "text": "Hello there."},
+				{"start": 1.2, "end": 2.5, "text": "General Kenobi."},
+			},
+		})
+	})
+	return s, NewOpenAITranscriber(openai.Whisper1, s.config())
+}
+
+// lastUpload parses the multipart upload of the last transcription request s
+// received.
+func lastUpload(t *testing.T, s *fakeServer) transcriptionUpload {
+	t.Helper()
+	reqs := s.requestsTo("/audio/transcriptions")
+	if len(reqs) == 0 {
+		t.Fatal("server received no transcription requests")
+	}
+	last := reqs[len(reqs)-1]
+	r := &http.Request{Method: http.MethodPost, Header: last.header, Body: io.NopCloser(bytes.NewReader(last.body))}
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("ParseMultipartForm() error = %v", err)
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		t.Fatalf("FormFile() error = %v", err)
+	}
+	audio, _ := io.ReadAll(file)
+	upload := transcriptionUpload{filename: header.Filename, audio: string(audio), fields: map[string]string{}}
+	for key, values := range r.MultipartForm.Value {
+		upload.fields[key] = values[0]
+	}
+	return upload
+}
+
+func TestOpenAITranscriber_Transcribe(t *testing.T) {
+	s, transcriber := newFakeTranscriptionServer(t)
+	transcriber.Language = "en"
+
+	got, err := transcriber.Transcribe(context.Background(), []byte("RIFF audio"), "audio/wav")
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if got != "Hello there. General Kenobi." {
+		t.Errorf("Transcribe() = %q, want the transcript", got)
+	}
+
+	want := transcriptionUpload{
+		filename: "audio.wav",
+		audio:    "RIFF audio",
+		fields:   map[string]string{"model": "whisper-1", "language": "en", "response_format": "json"},
+	}
+	if diff := cmp.Diff(want, lastUpload(t, s), cmp.AllowUnexported(transcriptionUpload{})); diff != "" {
+		t.Errorf("upload mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenAITranscriber_TranscribeSegments(t *testing.T) {
+	s, transcriber := newFakeTranscriptionServer(t)
+
+	got, err := transcriber.TranscribeSegments(context.Background(), []byte("ID3 audio"), "audio/mpeg")
+	if err != nil {
+		t.Fatalf("TranscribeSegments() error = %v", err)
+	}
+	want := &Transcription{
+		Text:     "Hello there. General Kenobi.",
+		Language: "english",
+		Segments: []TranscriptionSegment{
+			{Start: 0, End: 1.2, Text: "Hello there."},
+			{Start: 1.2, End: 2.5, Text: "General Kenobi."},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TranscribeSegments() mismatch (-want +got):\n%s", diff)
+	}
+	if upload := lastUpload(t, s); upload.filename != "audio.mp3" || upload.fields["response_format"] != "verbose_json" || upload.fields["timestamp_granularities[]"] != "segment" {
+		t.Errorf("upload = %+v, want an mp3 requesting verbose_json segments", upload)
+	}
+}
+
+func TestOpenAITranscriber_UnsupportedType(t *testing.T) {
+	transcriber := &OpenAITranscriber{ModelName: openai.Whisper1}
+	if _, err := transcriber.Transcribe(context.Background(), []byte("data"), "video/mp4"); err == nil {
+		t.Error("Transcribe() error = nil, want an unsupported audio type error")
+	}
+}
@@ -0,0 +1,25 @@
+package openai
+
+import (
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// TruncatedError reports a response cut off by the output token limit when
+// OpenAIModel.FailOnTruncation is set. Response holds the truncated response.
+type TruncatedError struct {
+	Response *model.LLMResponse
+}
+
+func (e *TruncatedError) Error() string {
+	return "response truncated at the output token limit"
+}
+
+// truncationError returns a TruncatedError for a resp that hit the token
+// limit when FailOnTruncation is set, and nil otherwise.
+func (o *OpenAIModel) truncationError(resp *model.LLMResponse) error {
+	if !o.FailOnTruncation || resp.FinishReason != genai.FinishReasonMaxTokens {
+		return nil
+	}
+	return &TruncatedError{Response: resp}
+}
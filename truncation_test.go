@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/genai"
+)
+
+func TestGenerateContent_FailOnTruncation(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		stream, strict bool
+	}{
+		{name: "tolerant"},
+		{name: "tolerant streaming", stream: true},
+		{name: "strict", strict: true},
+		{name: "strict streaming", stream: true, strict: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, strict := tt.stream, tt.strict
+			s, m := newFakeServer(t)
+			m.FailOnTruncation = strict
+			s.response = openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message:      openai.ChatCompletionMessage{Role: "assistant", Content: "Once upon a"},
+				FinishReason: openai.FinishReasonLength,
+			}}}
+			s.chunks = []openai.ChatCompletionStreamResponse{
+				deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Once upon a"}, openai.FinishReasonLength),
+			}
+
+			var final *genai.Content
+			var gotErr error
+			for resp, err := range m.GenerateContent(context.Background(), weatherRequest(), stream) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				if !resp.Partial {
+					final = resp.Content
+				}
+			}
+
+			if !strict {
+				if gotErr != nil || final == nil || final.Parts[0].Text != "Once upon a" {
+					t.Errorf("got content %+v, error %v, want the truncated text", final, gotErr)
+				}
+				return
+			}
+			var truncErr *TruncatedError
+			if !errors.As(gotErr, &truncErr) {
+				t.Fatalf("error = %v, want *TruncatedError", gotErr)
+			}
+			if final != nil {
+				t.Error("got a final response alongside the error")
+			}
+			if got := truncErr.Response.Content.Parts[0].Text; got != "Once upon a" || truncErr.Response.FinishReason != genai.FinishReasonMaxTokens {
+				t.Errorf("TruncatedError.Response = %q (%s), want the truncated text", got, truncErr.Response.FinishReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package openai
+
+import "google.golang.org/adk/model"
+
+// EstimatedCompletionTokensMetadataKey is the LLMResponse.CustomMetadata key
+// under which partial streaming responses carry, as an int, a running
+// estimate of the completion tokens streamed so far when
+// OpenAIModel.StreamUsageEstimates is set. Estimates are counted locally from
+// the streamed text and reasoning; the authoritative count remains the final
+// response's UsageMetadata.
+const EstimatedCompletionTokensMetadataKey = "estimated_completion_tokens"
+
+// estimateStreamUsage wraps emit so that every partial response it passes on
+// carries the running completion token estimate. emit is returned unchanged
+// without StreamUsageEstimates or when no tokenizer is available.
+func (o *OpenAIModel) estimateStreamUsage(emit func(*model.LLMResponse) bool) func(*model.LLMResponse) bool {
+	if !o.StreamUsageEstimates {
+		return emit
+	}
+	enc, err := encodingForModel(o.ModelName)
+	if err != nil {
+		return emit
+	}
+	total := 0
+	return func(resp *model.LLMResponse) bool {
+		if resp.Content != nil {
+			for _, part := range resp.Content.Parts {
+				if part.Text != "" {
+					total += len(enc.Encode(part.Text, nil, nil))
+				}
+			}
+		}
+		setCustomMetadata(resp, EstimatedCompletionTokensMetadataKey, total)
+		return emit(resp)
+	}
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestReadStream_StreamUsageEstimates(t *testing.T) {
+	UseOfflineEncodings()
+	chunks := func() []openai.ChatCompletionStreamResponse {
+		return []openai.ChatCompletionStreamResponse{
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{ReasoningContent: "The user wants a greeting."}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: "Hello"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: " there, how are you today?"}, ""),
+			deltaChunk(openai.ChatCompletionStreamChoiceDelta{Content: " Nice weather."}, openai.FinishReasonStop),
+		}
+	}
+
+	resps := collectStream(t, &OpenAIModel{ModelName: "gpt-4o", StreamUsageEstimates: true}, &fakeStream{chunks: chunks()})
+	last := 0
+	partials := 0
+	for _, resp := range resps {
+		estimate, ok := resp.CustomMetadata[EstimatedCompletionTokensMetadataKey].(int)
+		if !resp.Partial {
+			if ok {
+				t.Errorf("final response carries estimate %d", estimate)
+			}
+			continue
+		}
+		partials++
+		if !ok || estimate <= last {
+			t.Errorf("partial estimate = %v, want more than %d", resp.CustomMetadata[EstimatedCompletionTokensMetadataKey], last)
+		}
+		last = estimate
+	}
+	if partials != 4 {
+		t.Errorf("got %d partial responses, want 4", partials)
+	}
+
+	plain := collectStream(t, &OpenAIModel{ModelName: "gpt-4o"}, &fakeStream{chunks: chunks()})
+	if _, ok := plain[0].CustomMetadata[EstimatedCompletionTokensMetadataKey]; ok {
+		t.Error("estimate reported without StreamUsageEstimates")
+	}
+}
@@ -0,0 +1,22 @@
+package openai
+
+import "context"
+
+// userKey is the context key for the per-request end-user identifier.
+type userKey struct{}
+
+// ContextWithUser returns a copy of ctx whose requests identify the end user
+// as user. It takes precedence over OpenAIModel.User. OpenAI uses the value
+// for abuse monitoring, so pass a stable, opaque ID such as a hash of the
+// user's account name rather than personal details.
+func ContextWithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// user returns the end-user identifier to send with requests made under ctx.
+func (o *OpenAIModel) user(ctx context.Context) string {
+	if user, ok := ctx.Value(userKey{}).(string); ok && user != "" {
+		return user
+	}
+	return o.User
+}
@@ -0,0 +1,42 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionRequest_User(t *testing.T) {
+	tests := []struct {
+		name      string
+		modelUser string
+		ctxUser   string
+		want      string
+	}{
+		{name: "unset"},
+		{name: "model default", modelUser: "model-user", want: "model-user"},
+		{name: "context only", ctxUser: "ctx-user", want: "ctx-user"},
+		{name: "context overrides model", modelUser: "model-user", ctxUser: "ctx-user", want: "ctx-user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctxUser != "" {
+				ctx = ContextWithUser(ctx, tt.ctxUser)
+			}
+			m := &OpenAIModel{ModelName: "gpt-4o", User: tt.modelUser}
+			got, err := m.toOpenAIChatCompletionRequest(ctx, &model.LLMRequest{
+				Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+			})
+			if err != nil {
+				t.Fatalf("toOpenAIChatCompletionRequest() error = %v", err)
+			}
+			if got.User != tt.want {
+				t.Errorf("User = %q, want %q", got.User, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxStopSequences is the most stop sequences OpenAI accepts.
+const maxStopSequences = 4
+
+// Limits OpenAI places on request metadata.
+const (
+	maxMetadataPairs       = 16
+	maxMetadataKeyLength   = 64
+	maxMetadataValueLength = 512
+)
+
+// validateRequest checks req against the constraints OpenAI enforces and
+// returns every violation in one error, or nil.
+func validateRequest(req openai.ChatCompletionRequest) error {
+	var errs []error
+	if req.Model == "" {
+		errs = append(errs, errors.New("model is required"))
+	}
+	if len(req.Messages) == 0 {
+		errs = append(errs, errors.New("messages must not be empty"))
+	}
+	if req.Temperature < 0 || req.Temperature > 2 {
+		errs = append(errs, fmt.Errorf("temperature %v is outside [0, 2]", req.Temperature))
+	}
+	if req.TopP < 0 || req.TopP > 1 {
+		errs = append(errs, fmt.Errorf("top_p %v is outside [0, 1]", req.TopP))
+	}
+	if req.PresencePenalty < -2 || req.PresencePenalty > 2 {
+		errs = append(errs, fmt.Errorf("presence_penalty %v is outside [-2, 2]", req.PresencePenalty))
+	}
+	if req.FrequencyPenalty < -2 || req.FrequencyPenalty > 2 {
+		errs = append(errs, fmt.Errorf("frequency_penalty %v is outside [-2, 2]", req.FrequencyPenalty))
+	}
+	if len(req.Stop) > maxStopSequences {
+		errs = append(errs, fmt.Errorf("%d stop sequences given, at most %d are allowed", len(req.Stop), maxStopSequences))
+	}
+	if req.TopLogProbs < 0 || req.TopLogProbs > 20 {
+		errs = append(errs, fmt.Errorf("top_logprobs %d is outside [0, 20]", req.TopLogProbs))
+	}
+	if req.TopLogProbs > 0 && !req.LogProbs {
+		errs = append(errs, errors.New("top_logprobs requires logprobs"))
+	}
+
+	toolNames := make(map[string]bool, len(req.Tools))
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		if toolNames[tool.Function.Name] {
+			errs = append(errs, fmt.Errorf("tool %q is declared more than once", tool.Function.Name))
+		}
+		toolNames[tool.Function.Name] = true
+	}
+
+	errs = append(errs, validateMessageSequence(req.Messages)...)
+	if capabilitiesForModel(req.Model).noVision {
+		for i, msg := range req.Messages {
+			for _, part := range msg.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeImageURL {
+					errs = append(errs, fmt.Errorf("message %d: model %q does not accept image input", i, req.Model))
+					break
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid chat completion request: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateMessageSequence checks that every tool message carries a tool call
+// ID answering a tool call made by the assistant message preceding its run of
+// tool messages, and that every such tool call is answered.
+func validateMessageSequence(msgs []openai.ChatCompletionMessage) []error {
+	var errs []error
+	pending := map[string]bool{}
+	pendingFrom := 0
+	unanswered := func() {
+		ids := make([]string, 0, len(pending))
+		for id := range pending {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			errs = append(errs, fmt.Errorf("message %d: tool call %q is not answered by a tool message", pendingFrom, id))
+		}
+	}
+	for i, msg := range msgs {
+		switch msg.Role {
+		case openai.ChatMessageRoleTool:
+			switch {
+			case msg.ToolCallID == "":
+				errs = append(errs, fmt.Errorf("message %d: tool message has no tool call ID", i))
+			case !pending[msg.ToolCallID]:
+				errs = append(errs, fmt.Errorf("message %d: tool message for %q does not follow an assistant tool call with that ID", i, msg.ToolCallID))
+			}
+			delete(pending, msg.ToolCallID)
+		case openai.ChatMessageRoleAssistant:
+			unanswered()
+			pending = map[string]bool{}
+			pendingFrom = i
+			for _, call := range msg.ToolCalls {
+				pending[call.ID] = true
+			}
+		default:
+			unanswered()
+			pending = map[string]bool{}
+		}
+	}
+	unanswered()
+	return errs
+}
+
+// validateMetadata checks metadata against OpenAI's limits on the number of
+// pairs and the length of keys and values.
+func validateMetadata(metadata map[string]string) error {
+	var errs []error
+	if len(metadata) > maxMetadataPairs {
+		errs = append(errs, fmt.Errorf("%d metadata pairs given, at most %d are allowed", len(metadata), maxMetadataPairs))
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if utf8.RuneCountInString(key) > maxMetadataKeyLength {
+			errs = append(errs, fmt.Errorf("metadata key %q is longer than %d characters", key, maxMetadataKeyLength))
+		}
+		if utf8.RuneCountInString(metadata[key]) > maxMetadataValueLength {
+			errs = append(errs, fmt.Errorf("metadata value for %q is longer than %d characters", key, maxMetadataValueLength))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid metadata: %w", errors.Join(errs...))
+	}
+	return nil
+}
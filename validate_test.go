@@ -0,0 +1,156 @@
+package openai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+func TestValidateRequest(t *testing.T) {
+	valid := func() openai.ChatCompletionRequest {
+		return openai.ChatCompletionRequest{
+			Model:    "gpt-4o",
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello"}},
+		}
+	}
+	weatherTool := openai.Tool{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{Name: "weather"}}
+
+	tests := []struct {
+		name    string
+		mutate  func(*openai.ChatCompletionRequest)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*openai.ChatCompletionRequest) {},
+		},
+		{
+			name: "sampling parameters out of range",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Temperature = 2.5
+				r.TopP = 1.5
+				r.PresencePenalty = -3
+				r.Stop = []string{"a", "b", "c", "d", "e"}
+			},
+			wantErr: []string{
+				"temperature 2.5 is outside [0, 2]",
+				"top_p 1.5 is outside [0, 1]",
+				"presence_penalty -3 is outside [-2, 2]",
+				"5 stop sequences given, at most 4 are allowed",
+			},
+		},
+		{
+			name: "duplicate tools",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Tools = []openai.Tool{weatherTool, weatherTool}
+			},
+			wantErr: []string{`tool "weather" is declared more than once`},
+		},
+		{
+			name: "tool message without tool call",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Messages = append(r.Messages,
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Let me check."},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+				)
+			},
+			wantErr: []string{`message 2: tool message for "call_1" does not follow an assistant tool call with that ID`},
+		},
+		{
+			name: "tool message without tool call ID",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Messages = append(r.Messages,
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}}},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, Content: "rainy"},
+				)
+			},
+			wantErr: []string{"message 3: tool message has no tool call ID"},
+		},
+		{
+			name: "unanswered tool call",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Messages = append(r.Messages,
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}, {ID: "call_2"}}},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: "And tomorrow?"},
+				)
+			},
+			wantErr: []string{`message 1: tool call "call_2" is not answered by a tool message`},
+		},
+		{
+			name: "image for a model without vision",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Model = "gpt-3.5-turbo"
+				r.Messages = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "What is this?"},
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+				}}}
+			},
+			wantErr: []string{`message 0: model "gpt-3.5-turbo" does not accept image input`},
+		},
+		{
+			name: "image for a vision model",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Messages = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+				}}}
+			},
+		},
+		{
+			name: "tool messages answering tool calls",
+			mutate: func(r *openai.ChatCompletionRequest) {
+				r.Messages = append(r.Messages,
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{ID: "call_1"}, {ID: "call_2"}}},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_2", Content: "sunny"},
+					openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "rainy"},
+				)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.mutate(&req)
+			err := validateRequest(req)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("validateRequest() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateRequest() error = nil, want error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateContent_ValidateRequests(t *testing.T) {
+	s, m := newFakeServer(t)
+	WithValidateRequests()(m)
+
+	temperature := float32(3)
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: "Hello"}}}},
+		Config:   &genai.GenerateContentConfig{Temperature: &temperature},
+	}
+	for _, err := range m.GenerateContent(context.Background(), req, false) {
+		if err == nil || !strings.Contains(err.Error(), "temperature 3 is outside [0, 2]") {
+			t.Errorf("GenerateContent() error = %v, want temperature violation", err)
+		}
+	}
+	if len(s.requestsTo("/chat/completions")) > 0 {
+		t.Error("invalid request was sent to the server")
+	}
+}
@@ -0,0 +1,48 @@
+package openai
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// UnsupportedMediaError reports a content part whose media type cannot be
+// sent through chat completions, such as video, or a non-image tool result.
+type UnsupportedMediaError struct {
+	MIMEType string
+}
+
+func (e *UnsupportedMediaError) Error() string {
+	return fmt.Sprintf("unsupported media type %q: chat completions cannot carry it", e.MIMEType)
+}
+
+// videoPartError returns an UnsupportedMediaError for a part carrying video,
+// either as an inline blob or file data with a video/* MIME type, or any
+// media part annotated with VideoMetadata. Other parts return nil.
+func videoPartError(part *genai.Part) error {
+	var mimeType string
+	switch {
+	case part.InlineData != nil:
+		mimeType = part.InlineData.MIMEType
+	case part.FileData != nil:
+		mimeType = part.FileData.MIMEType
+		if mimeType == "" {
+			if u, err := url.Parse(part.FileData.FileURI); err == nil {
+				mimeType = mime.TypeByExtension(strings.ToLower(path.Ext(u.Path)))
+			}
+		}
+	default:
+		return nil
+	}
+	if part.VideoMetadata != nil || strings.HasPrefix(strings.ToLower(mimeType), "video/") {
+		if mimeType == "" {
+			mimeType = "video/*"
+		}
+		return &UnsupportedMediaError{MIMEType: mimeType}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestToOpenAIChatCompletionMessage_Video(t *testing.T) {
+	tests := []struct {
+		name     string
+		part     *genai.Part
+		wantMIME string
+	}{
+		{
+			name:     "inline mp4",
+			part:     &genai.Part{InlineData: &genai.Blob{MIMEType: "video/mp4", Data: []byte{0, 0, 0, 0x18, 'f', 't', 'y', 'p'}}},
+			wantMIME: "video/mp4",
+		},
+		{
+			name:     "remote file by extension",
+			part:     &genai.Part{FileData: &genai.FileData{FileURI: "https://example.com/clip.mp4"}},
+			wantMIME: "video/mp4",
+		},
+		{
+			name: "video metadata",
+			part: &genai.Part{
+				FileData:      &genai.FileData{FileURI: "gs://bucket/clip"},
+				VideoMetadata: &genai.VideoMetadata{FPS: genai.Ptr(1.0)},
+			},
+			wantMIME: "video/*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &OpenAIModel{}
+			_, err := m.toOpenAIChatCompletionMessage(&genai.Content{
+				Role:  "user",
+				Parts: []*genai.Part{{Text: "Summarize this clip"}, tt.part},
+			})
+			var mediaErr *UnsupportedMediaError
+			if !errors.As(err, &mediaErr) {
+				t.Fatalf("toOpenAIChatCompletionMessage() error = %v, want UnsupportedMediaError", err)
+			}
+			if mediaErr.MIMEType != tt.wantMIME {
+				t.Errorf("MIMEType = %q, want %q", mediaErr.MIMEType, tt.wantMIME)
+			}
+		})
+	}
+}